@@ -12,4 +12,536 @@ limitations under the License.
 
 package v1alpha1
 
-func (_ *Dashboard) Hub() {}
+import (
+	"encoding/json"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1alpha1panels "kubesphere.io/monitoring-dashboard/api/v1alpha1/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	v1alpha2panels "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	v1alpha2templatings "kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// PreservedSpecAnnotation holds the v1alpha2 spec of a dashboard served as v1alpha1,
+// when v1alpha1 cannot represent all of it. It is read back when the object
+// is converted to v1alpha2 again, so a round trip through v1alpha1 keeps
+// panel types and time overrides, annotations, tags, templating options and the like.
+const PreservedSpecAnnotation = "monitoring.kubesphere.io/v1alpha2-spec"
+
+var _ conversion.Convertible = &Dashboard{}
+var _ conversion.Convertible = &ClusterDashboard{}
+
+// ConvertTo converts this Dashboard to the Hub version (v1alpha2)
+func (src *Dashboard) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha2.Dashboard)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	convertSpecToHub(&src.Spec, &dst.Spec)
+	return restoreSpec(&dst.ObjectMeta, &dst.Spec)
+}
+
+// ConvertFrom converts from the Hub version (v1alpha2) to this version
+func (dst *Dashboard) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha2.Dashboard)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	convertSpecFromHub(&src.Spec, &dst.Spec)
+	return preserveSpec(&dst.ObjectMeta, &src.Spec, &dst.Spec)
+}
+
+// ConvertTo converts this ClusterDashboard to the Hub version (v1alpha2)
+func (src *ClusterDashboard) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha2.ClusterDashboard)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	convertSpecToHub(&src.Spec, &dst.Spec)
+	return restoreSpec(&dst.ObjectMeta, &dst.Spec)
+}
+
+// ConvertFrom converts from the Hub version (v1alpha2) to this version
+func (dst *ClusterDashboard) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha2.ClusterDashboard)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	convertSpecFromHub(&src.Spec, &dst.Spec)
+	return preserveSpec(&dst.ObjectMeta, &src.Spec, &dst.Spec)
+}
+
+func convertSpecToHub(src *DashboardSpec, dst *v1alpha2.DashboardSpec) {
+	dst.Title = src.Title
+	dst.Description = src.Description
+	dst.Time = time.Time{
+		From: src.Time.From,
+		To:   src.Time.To,
+	}
+
+	pls := []*v1alpha2panels.Panel{}
+	for _, panel := range src.Panels {
+
+		dstPanel := v1alpha2panels.Panel{
+			CommonPanel: v1alpha2panels.CommonPanel{
+				Title:      panel.PanelMeta.Title,
+				Id:         panel.PanelMeta.Id,
+				Type:       string(panel.PanelMeta.Type),
+				Datasource: datasources.FromName(src.DataSource),
+			},
+		}
+
+		for _, target := range panel.Targets {
+			dstPanel.CommonPanel.Targets = append(dstPanel.CommonPanel.Targets, v1alpha2panels.Target{
+				Expression:   target.Expression,
+				LegendFormat: target.LegendFormat,
+				RefID:        target.RefID,
+				Step:         target.Step,
+			})
+		}
+
+		switch panel.Type {
+		case PanelGraph:
+			graph := panel.Graph
+			if graph != nil {
+				if graph.Description != "" {
+					description := graph.Description
+					dstPanel.CommonPanel.Description = &description
+				}
+				dstPanel.CommonPanel.Colors = graph.Colors
+
+				var yaxes []v1alpha2panels.Axis
+				for _, yaxis := range graph.Yaxes {
+					yaxes = append(yaxes, v1alpha2panels.Axis{
+						Decimals: yaxis.Decimals,
+						Format:   yaxis.Format,
+					})
+				}
+				dstPanel.GraphPanel = &v1alpha2panels.GraphPanel{
+					Bars:  graph.Bars,
+					Lines: graph.Lines,
+					Stack: graph.Stack,
+					Yaxes: yaxes,
+				}
+			}
+		case PanelSingleStat:
+			singlestat := panel.SingleStat
+			if singlestat != nil {
+				dstPanel.CommonPanel.Decimals = singlestat.Decimals
+				dstPanel.CommonPanel.Format = singlestat.Format
+			}
+		}
+
+		pls = append(pls, &dstPanel)
+	}
+	dst.Panels = pls
+
+	for _, temp := range src.Templatings {
+		dst.Templatings = append(dst.Templatings, hubVariable(temp.Name, temp.Query))
+	}
+}
+
+func convertSpecFromHub(src *v1alpha2.DashboardSpec, dst *DashboardSpec) {
+	dst.Title = src.Title
+	dst.Description = src.Description
+	dst.Time = Time{
+		From: src.Time.From,
+		To:   src.Time.To,
+	}
+
+	// v1alpha1 has a single datasource for the whole dashboard
+	dst.DataSource = hubDatasource(src)
+
+	pls := []Panel{}
+	for _, panel := range v1alpha2panels.Flatten(src.Panels) {
+
+		dstPanel := Panel{
+			PanelMeta: PanelMeta{
+				Title: panel.Title,
+				Id:    panel.Id,
+				Type:  downConvertedType(panel.Type),
+			},
+		}
+
+		for _, target := range panel.CommonPanel.Targets {
+			dstPanel.Targets = append(dstPanel.Targets, v1alpha1panels.Target{
+				Expression:   target.Expression,
+				LegendFormat: target.LegendFormat,
+				RefID:        targetRefID(target),
+				Step:         target.Step,
+			})
+		}
+
+		switch dstPanel.Type {
+		case PanelGraph:
+			graph := v1alpha1panels.Graph{
+				Colors: panel.CommonPanel.Colors,
+			}
+			if panel.CommonPanel.Description != nil {
+				graph.Description = *panel.CommonPanel.Description
+			}
+			if panel.GraphPanel != nil {
+				graph.Bars = panel.GraphPanel.Bars
+				graph.Lines = panel.GraphPanel.Lines
+				graph.Stack = panel.GraphPanel.Stack
+				for _, yaxis := range panel.GraphPanel.Yaxes {
+					graph.Yaxes = append(graph.Yaxes, v1alpha1panels.Yaxis{
+						Decimals: yaxis.Decimals,
+						Format:   yaxis.Format,
+					})
+				}
+			}
+			if panel.Type == v1alpha2panels.TypeTimeSeries {
+				graph.Bars, graph.Lines, graph.Stack = timeSeriesStyle(panel.TimeSeriesPanel)
+				if panel.Format != "" || panel.Decimals != nil {
+					graph.Yaxes = []v1alpha1panels.Yaxis{{
+						Decimals: int64Value(panel.Decimals),
+						Format:   panel.Format,
+					}}
+				}
+			}
+			if panel.Type == v1alpha2panels.TypeHeatmap {
+				graph.Lines = true
+				if heatmap := panel.HeatmapPanel; heatmap != nil && (heatmap.YAxis.Format != "" || heatmap.YAxis.Decimals != 0) {
+					graph.Yaxes = []v1alpha1panels.Yaxis{{
+						Decimals: heatmap.YAxis.Decimals,
+						Format:   heatmap.YAxis.Format,
+					}}
+				}
+			}
+			if !reflect.DeepEqual(graph, v1alpha1panels.Graph{}) {
+				dstPanel.Graph = &graph
+			}
+		case PanelSingleStat:
+			if panel.CommonPanel.Decimals != nil || panel.CommonPanel.Format != "" {
+				dstPanel.SingleStat = &v1alpha1panels.SingleStat{
+					Decimals: panel.CommonPanel.Decimals,
+					Format:   panel.CommonPanel.Format,
+				}
+			}
+		}
+
+		pls = append(pls, dstPanel)
+	}
+	dst.Panels = pls
+
+	for _, temp := range src.Templatings {
+		dst.Templatings = append(dst.Templatings, Templating{
+			Name:  temp.Name,
+			Query: variableQuery(temp),
+		})
+	}
+}
+
+// hubDatasource picks the first panel datasource as the datasource of the whole dashboard,
+// looking at the targets of the panels using the mixed datasource, once the inputs are resolved
+func hubDatasource(spec *v1alpha2.DashboardSpec) string {
+	resolved := spec.DeepCopy()
+	resolved.ResolveDatasources()
+	for _, panel := range v1alpha2panels.Flatten(resolved.Panels) {
+		refs := []*datasources.DatasourceRef{panel.Datasource}
+		if panel.Datasource.IsMixed() {
+			for _, target := range panel.Targets {
+				refs = append(refs, target.Datasource)
+			}
+		}
+		for _, ref := range refs {
+			if !ref.IsMixed() && ref.String() != "" {
+				return ref.String()
+			}
+		}
+	}
+	return ""
+}
+
+// preserveSpec stashes the hub spec in the annotations of the converted object,
+// unless converting dst back gives the same spec anyway. A spec stashed earlier and
+// copied along with the metadata of the hub is dropped then, it would be restored instead.
+func preserveSpec(meta *metav1.ObjectMeta, src *v1alpha2.DashboardSpec, dst *DashboardSpec) error {
+	var restored v1alpha2.DashboardSpec
+	convertSpecToHub(dst, &restored)
+	if equality.Semantic.DeepEqual(src, &restored) {
+		delete(meta.Annotations, PreservedSpecAnnotation)
+		if len(meta.Annotations) == 0 {
+			meta.Annotations = nil
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[PreservedSpecAnnotation] = string(data)
+	return nil
+}
+
+// restoreSpec reads the stashed hub spec back, and applies to it
+// whatever was changed in the fields v1alpha1 can represent
+func restoreSpec(meta *metav1.ObjectMeta, spec *v1alpha2.DashboardSpec) error {
+	data, ok := meta.Annotations[PreservedSpecAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(meta.Annotations, PreservedSpecAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+
+	var preserved v1alpha2.DashboardSpec
+	if err := json.Unmarshal([]byte(data), &preserved); err != nil {
+		return err
+	}
+
+	preserved.Title = spec.Title
+	preserved.Description = spec.Description
+	preserved.Time.From = spec.Time.From
+	preserved.Time.To = spec.Time.To
+
+	// per panel datasources survive as long as the dashboard datasource is unchanged
+	keepDatasources := hubDatasource(&preserved) == hubDatasource(spec)
+
+	// panels are matched by ID, in order, and must keep their type
+	flat := v1alpha2panels.Flatten(preserved.Panels)
+	previous := map[int64][]*v1alpha2panels.Panel{}
+	for _, panel := range flat {
+		previous[panel.Id] = append(previous[panel.Id], panel)
+	}
+	pls := []*v1alpha2panels.Panel{}
+	unchanged := len(spec.Panels) == len(flat)
+	for i, panel := range spec.Panels {
+		if candidates := previous[panel.Id]; len(candidates) > 0 && downConvertedType(candidates[0].Type) == PanelType(panel.Type) {
+			previous[panel.Id] = candidates[1:]
+			restorePanel(candidates[0], panel, keepDatasources)
+			panel = candidates[0]
+		}
+		unchanged = unchanged && panel == flat[i]
+		pls = append(pls, panel)
+	}
+
+	// v1alpha1 lists the panels nested in rows after their row. Rows keep their panels
+	// unless panels were added, removed or moved, in which case all panels stay at the top level.
+	if !unchanged {
+		for _, panel := range pls {
+			if panel.RowPanel != nil {
+				panel.RowPanel.Panels = nil
+				if reflect.DeepEqual(*panel.RowPanel, v1alpha2panels.RowPanel{}) {
+					panel.RowPanel = nil
+				}
+			}
+		}
+		preserved.Panels = pls
+	}
+
+	variables := map[string]v1alpha2templatings.TemplateVar{}
+	for _, variable := range preserved.Templatings {
+		if _, ok := variables[variable.Name]; !ok {
+			variables[variable.Name] = variable
+		}
+	}
+	var templatings []v1alpha2templatings.TemplateVar
+	for _, variable := range spec.Templatings {
+		// only query variables keep their query in v1alpha1, the others are kept unless given one
+		if candidate, ok := variables[variable.Name]; ok {
+			switch query := variableQuery(variable); {
+			case candidate.Type == v1alpha2templatings.TypeQuery && candidate.QueryVariable != nil:
+				candidate.QueryVariable.Query = query
+				variable = candidate
+			case candidate.Type == v1alpha2templatings.TypeQuery && query != "":
+				candidate.QueryVariable = variable.QueryVariable
+				variable = candidate
+			case query == "":
+				variable = candidate
+			}
+		}
+		templatings = append(templatings, variable)
+	}
+	preserved.Templatings = templatings
+
+	*spec = preserved
+	return nil
+}
+
+// restorePanel applies the fields of panel that v1alpha1 can represent to the preserved panel
+func restorePanel(preserved, panel *v1alpha2panels.Panel, keepDatasource bool) {
+	preserved.Title = panel.Title
+	if !keepDatasource {
+		preserved.Datasource = panel.Datasource
+	}
+	preserved.Targets = restoreTargets(preserved.Targets, panel.Targets)
+
+	graph := panel.GraphPanel
+	if graph == nil {
+		graph = &v1alpha2panels.GraphPanel{}
+	}
+
+	switch preserved.Type {
+	case v1alpha2panels.TypeGraph, v1alpha2panels.TypeTimeSeries, v1alpha2panels.TypeHeatmap:
+		if stringValue(preserved.Description) != stringValue(panel.Description) {
+			preserved.Description = panel.Description
+		}
+		preserved.Colors = panel.Colors
+	}
+
+	switch preserved.Type {
+	case v1alpha2panels.TypeGraph:
+		if preserved.GraphPanel == nil {
+			preserved.GraphPanel = &v1alpha2panels.GraphPanel{}
+		}
+		preserved.Bars = graph.Bars
+		preserved.Lines = graph.Lines
+		preserved.Stack = graph.Stack
+		var yaxes []v1alpha2panels.Axis
+		for i, yaxis := range graph.Yaxes {
+			if i < len(preserved.Yaxes) {
+				preserved.Yaxes[i].Decimals = yaxis.Decimals
+				preserved.Yaxes[i].Format = yaxis.Format
+				yaxis = preserved.Yaxes[i]
+			}
+			yaxes = append(yaxes, yaxis)
+		}
+		preserved.Yaxes = yaxes
+		if reflect.DeepEqual(*preserved.GraphPanel, v1alpha2panels.GraphPanel{}) {
+			preserved.GraphPanel = nil
+		}
+	case v1alpha2panels.TypeTimeSeries:
+		// time series panels are served as graphs, so only what changed in the graph is applied
+		bars, lines, stack := timeSeriesStyle(preserved.TimeSeriesPanel)
+		if preserved.TimeSeriesPanel == nil {
+			preserved.TimeSeriesPanel = &v1alpha2panels.TimeSeriesPanel{}
+		}
+		if graph.Bars != bars || graph.Lines != lines {
+			switch {
+			case graph.Bars:
+				preserved.DrawStyle = v1alpha2panels.DrawStyleBars
+			case graph.Lines:
+				preserved.DrawStyle = v1alpha2panels.DrawStyleLine
+			default:
+				preserved.DrawStyle = v1alpha2panels.DrawStylePoints
+			}
+		}
+		if graph.Stack != stack {
+			preserved.StackingMode = v1alpha2panels.StackingNone
+			if graph.Stack {
+				preserved.StackingMode = v1alpha2panels.StackingNormal
+			}
+		}
+		if reflect.DeepEqual(*preserved.TimeSeriesPanel, v1alpha2panels.TimeSeriesPanel{}) {
+			preserved.TimeSeriesPanel = nil
+		}
+
+		var yaxis v1alpha2panels.Axis
+		if len(graph.Yaxes) > 0 {
+			yaxis = graph.Yaxes[0]
+		}
+		preserved.Format = yaxis.Format
+		if int64Value(preserved.Decimals) != yaxis.Decimals {
+			preserved.Decimals = &yaxis.Decimals
+		}
+	case v1alpha2panels.TypeHeatmap:
+		// heatmaps are served as graphs, of which only the y-axis applies
+		if preserved.HeatmapPanel == nil {
+			preserved.HeatmapPanel = &v1alpha2panels.HeatmapPanel{}
+		}
+		var yaxis v1alpha2panels.Axis
+		if len(graph.Yaxes) > 0 {
+			yaxis = graph.Yaxes[0]
+		}
+		preserved.HeatmapPanel.YAxis.Decimals = yaxis.Decimals
+		preserved.HeatmapPanel.YAxis.Format = yaxis.Format
+		if reflect.DeepEqual(*preserved.HeatmapPanel, v1alpha2panels.HeatmapPanel{}) {
+			preserved.HeatmapPanel = nil
+		}
+	case v1alpha2panels.TypeSinglestat:
+		preserved.Decimals = panel.Decimals
+		preserved.Format = panel.Format
+	}
+}
+
+// hubVariable is the v1alpha2 counterpart of a v1alpha1 templating, which is always a query variable
+func hubVariable(name, query string) v1alpha2templatings.TemplateVar {
+	variable := v1alpha2templatings.TemplateVar{
+		CommonVariable: v1alpha2templatings.CommonVariable{Name: name, Type: v1alpha2templatings.TypeQuery},
+	}
+	if query != "" {
+		variable.QueryVariable = &v1alpha2templatings.QueryVariable{Query: query}
+	}
+	return variable
+}
+
+// variableQuery is the query of a query variable, empty for the other kinds
+func variableQuery(variable v1alpha2templatings.TemplateVar) string {
+	if variable.QueryVariable == nil {
+		return ""
+	}
+	return variable.QueryVariable.Query
+}
+
+// downConvertedType is the v1alpha1 panel type serving the given v1alpha2 panel type
+func downConvertedType(t string) PanelType {
+	if t == v1alpha2panels.TypeTimeSeries || t == v1alpha2panels.TypeHeatmap {
+		return PanelGraph
+	}
+	return PanelType(t)
+}
+
+// timeSeriesStyle tells how a time series panel looks as a graph
+func timeSeriesStyle(timeseries *v1alpha2panels.TimeSeriesPanel) (bars, lines, stack bool) {
+	if timeseries == nil {
+		return false, true, false
+	}
+	switch timeseries.DrawStyle {
+	case v1alpha2panels.DrawStyleBars:
+		bars = true
+	case v1alpha2panels.DrawStylePoints:
+	default:
+		lines = true
+	}
+	stack = timeseries.StackingMode == v1alpha2panels.StackingNormal || timeseries.StackingMode == v1alpha2panels.StackingPercent
+	return bars, lines, stack
+}
+
+// restoreTargets matches targets by refId, and applies the fields v1alpha1 can represent
+func restoreTargets(preserved, targets []v1alpha2panels.Target) []v1alpha2panels.Target {
+	previous := map[int64][]v1alpha2panels.Target{}
+	for _, target := range preserved {
+		refID := targetRefID(target)
+		previous[refID] = append(previous[refID], target)
+	}
+
+	var restored []v1alpha2panels.Target
+	for _, target := range targets {
+		if candidates := previous[target.RefID]; len(candidates) > 0 {
+			previous[target.RefID] = candidates[1:]
+			candidate := candidates[0]
+			candidate.Expression = target.Expression
+			candidate.LegendFormat = target.LegendFormat
+			candidate.Step = target.Step
+			target = candidate
+		}
+		restored = append(restored, target)
+	}
+	return restored
+}
+
+// targetRefID is the numeric refId of a target, numbered after its Grafana refId when it has none
+func targetRefID(target v1alpha2panels.Target) int64 {
+	if target.RefID == 0 {
+		return v1alpha2panels.RefIDFromName(target.RefName)
+	}
+	return target.RefID
+}
+
+func int64Value(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
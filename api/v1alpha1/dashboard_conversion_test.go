@@ -0,0 +1,590 @@
+package v1alpha1
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/json"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha1/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	v1alpha2panels "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	v1alpha2templatings "kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	datasource = datasources.DatasourceRef{Name: "prometheus"}
+
+	v1alpha1DashboardString = `
+{
+	"spec": {
+		"datasource": "prometheus",
+		"panels": [
+			{
+				"id": 1,
+				"title": "Current QPS",
+				"type": "singlestat",
+				"targets": [{
+					"expr": "vector(1)"
+				}]
+			},
+			{
+				"id": 2,
+				"title": "Connections and Threads",
+				"type": "row"
+			},
+			{
+				"id": 3,
+				"title": "Connections",
+				"type": "graph",
+				"targets": [{
+					"expr": "vector(1)"
+				}]
+			}
+		],
+		"title": "MySQL Overview"
+	}
+}  
+`
+
+	v1alpha2DashboardString = `
+{
+	"spec":{
+		"panels": [
+		{
+			"id": 1,
+			"title": "Current QPS",
+			"type": "singlestat",
+			"datasource": "prometheus",
+			"targets": [{
+				"expr": "vector(1)"
+			}]
+		},
+		{
+			"id": 2,
+			"title": "Connections and Threads",
+			"type": "row",
+			"datasource": "prometheus"
+		},
+		{
+			"id": 3,
+			"title": "Connections",
+			"type": "graph",
+			"targets": [{
+				"expr": "vector(1)"
+			}],
+			"datasource": "prometheus"
+		}
+	],
+	"title": "MySQL Overview"
+	}	
+}
+`
+	expectedV1alpha1Dashboard = Dashboard{
+		Spec: DashboardSpec{
+			Title:      "MySQL Overview",
+			DataSource: "prometheus",
+			Panels: []Panel{{
+				PanelMeta: PanelMeta{
+					Id:    1,
+					Type:  "singlestat",
+					Title: "Current QPS",
+				},
+				Targets: []panels.Target{{
+					Expression: "vector(1)",
+				}},
+			}, {
+				PanelMeta: PanelMeta{
+					Id:    2,
+					Title: "Connections and Threads",
+					Type:  "row",
+				},
+			}, {
+				PanelMeta: PanelMeta{
+					Id:    3,
+					Title: "Connections",
+					Type:  "graph",
+				},
+				Targets: []panels.Target{{
+					Expression: "vector(1)",
+				}},
+			}},
+		},
+	}
+
+	expectedV1alpha2Dashboard = v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Title: "MySQL Overview",
+			Panels: []*v1alpha2panels.Panel{
+				{
+					CommonPanel: v1alpha2panels.CommonPanel{
+						Id:         1,
+						Type:       "singlestat",
+						Title:      "Current QPS",
+						Datasource: &datasource,
+						Targets: []v1alpha2panels.Target{{
+							Expression: "vector(1)",
+						}},
+					},
+				},
+				{
+					CommonPanel: v1alpha2panels.CommonPanel{
+						Id:         2,
+						Type:       "row",
+						Title:      "Connections and Threads",
+						Datasource: &datasource,
+					},
+				},
+				{
+					CommonPanel: v1alpha2panels.CommonPanel{
+						Id:         3,
+						Title:      "Connections",
+						Type:       "graph",
+						Datasource: &datasource,
+						Targets: []v1alpha2panels.Target{{
+							Expression: "vector(1)",
+						}},
+					},
+				}},
+		},
+	}
+)
+
+func TestV1alpha1ToV1alpha2(t *testing.T) {
+
+	var v1alpha1ActualDashboard Dashboard
+	var v1alpha2ActualDashboard v1alpha2.Dashboard
+
+	err := json.Unmarshal([]byte(v1alpha1DashboardString), &v1alpha1ActualDashboard)
+	if err != nil {
+		panic(err)
+	}
+
+	require.NoError(t, v1alpha1ActualDashboard.ConvertTo(&v1alpha2ActualDashboard))
+
+	require.EqualValues(t, expectedV1alpha2Dashboard, v1alpha2ActualDashboard)
+}
+
+func TestV1alpha2ToV1alpha1(t *testing.T) {
+
+	var v1alpha1ActualDashboard Dashboard
+	var v1alpha2ActualDashboard v1alpha2.Dashboard
+
+	err := json.Unmarshal([]byte(v1alpha2DashboardString), &v1alpha2ActualDashboard)
+	if err != nil {
+		panic(err)
+	}
+
+	require.NoError(t, v1alpha1ActualDashboard.ConvertFrom(&v1alpha2ActualDashboard))
+
+	require.EqualValues(t, expectedV1alpha1Dashboard, v1alpha1ActualDashboard)
+}
+
+func TestConvertGalleryDashboard(t *testing.T) {
+	req := require.New(t)
+
+	data, err := ioutil.ReadFile("../../contrib/gallery/redis.yaml")
+	req.NoError(err)
+
+	var dashboard Dashboard
+	req.NoError(yaml.Unmarshal(data, &dashboard))
+	req.Equal("redis-overview", dashboard.Name)
+
+	var hub v1alpha2.Dashboard
+	req.NoError(dashboard.ConvertTo(&hub))
+	req.Equal(dashboard.Name, hub.Name)
+	req.Len(hub.Spec.Panels, len(dashboard.Spec.Panels))
+
+	graph := hub.Spec.Panels[0]
+	req.Equal("Hits / Misses per Sec", graph.Title)
+	req.Equal(&datasource, graph.Datasource)
+	req.Len(graph.Colors, 7)
+	req.NotNil(graph.GraphPanel)
+	req.True(graph.Lines)
+	req.Equal("Byte/s", hub.Spec.Panels[1].Yaxes[0].Format)
+	req.Len(graph.Targets, 2)
+
+	// converting back gives the original spec
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Equal(dashboard.Spec, spoke.Spec)
+
+	cluster := ClusterDashboard{Spec: dashboard.Spec}
+	var clusterHub v1alpha2.ClusterDashboard
+	req.NoError(cluster.ConvertTo(&clusterHub))
+	req.Equal(hub.Spec, clusterHub.Spec)
+}
+
+func TestGalleryRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../../contrib/gallery/*.yaml")
+	require.NoError(t, err)
+	clusterFiles, err := filepath.Glob("../../contrib/gallery/clusterdashboards/*.yaml")
+	require.NoError(t, err)
+	files = append(files, clusterFiles...)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(filepath.Dir(file))+"/"+filepath.Base(file), func(t *testing.T) {
+			req := require.New(t)
+
+			data, err := ioutil.ReadFile(file)
+			req.NoError(err)
+
+			if !strings.Contains(string(data), "apiVersion: monitoring.kubesphere.io/v1alpha2") {
+				// v1alpha1 -> v1alpha2 -> v1alpha1
+				var dashboard, actual Dashboard
+				var hub v1alpha2.Dashboard
+				req.NoError(yaml.Unmarshal(data, &dashboard))
+				req.NoError(dashboard.ConvertTo(&hub))
+				req.NoError(actual.ConvertFrom(&hub))
+				req.Equal(dashboard.ObjectMeta, actual.ObjectMeta)
+				req.Equal(dashboard.Spec, actual.Spec)
+				return
+			}
+
+			// v1alpha2 -> v1alpha1 -> v1alpha2
+			var hub, actual v1alpha2.ClusterDashboard
+			var spoke ClusterDashboard
+			req.NoError(yaml.Unmarshal(data, &hub))
+			req.NoError(spoke.ConvertFrom(&hub))
+			req.NoError(spoke.ConvertTo(&actual))
+			req.Equal(hub.ObjectMeta, actual.ObjectMeta)
+
+			expected, err := json.Marshal(hub.Spec)
+			req.NoError(err)
+			roundTripped, err := json.Marshal(actual.Spec)
+			req.NoError(err)
+			req.JSONEq(string(expected), string(roundTripped))
+		})
+	}
+}
+
+func TestRestoreEditedSpec(t *testing.T) {
+	req := require.New(t)
+
+	description := "requests served"
+	hub := v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Title:    "Overview",
+			Tags:     []string{"nginx"},
+			Timezone: "utc",
+			Panels: []*v1alpha2panels.Panel{
+				{
+					CommonPanel: v1alpha2panels.CommonPanel{Id: 1, Type: "table", Title: "Pods", Datasource: &datasource},
+					TablePanel:  &v1alpha2panels.TablePanel{Scroll: true},
+				},
+				{
+					CommonPanel: v1alpha2panels.CommonPanel{Id: 2, Type: "graph", Title: "QPS", Description: &description,
+						Legend: &v1alpha2panels.Legend{Calcs: []v1alpha2panels.Calc{"mean"}}, Targets: []v1alpha2panels.Target{{RefID: 1, Expression: "x"}}},
+					GraphPanel: &v1alpha2panels.GraphPanel{Lines: true, Xaxis: v1alpha2panels.Axis{Format: "time"}},
+				},
+			},
+			Templatings: []v1alpha2templatings.TemplateVar{
+				{
+					CommonVariable: v1alpha2templatings.CommonVariable{Name: "namespace", Type: "query"},
+					QueryVariable: &v1alpha2templatings.QueryVariable{
+						Query:      "label_values(namespace)",
+						MultiValue: v1alpha2templatings.MultiValue{Multi: true},
+					},
+				},
+				{
+					CommonVariable:   v1alpha2templatings.CommonVariable{Name: "step", Type: "interval"},
+					IntervalVariable: &v1alpha2templatings.IntervalVariable{Intervals: []string{"1m", "5m"}},
+				},
+			},
+		},
+	}
+
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Contains(spoke.Annotations, PreservedSpecAnnotation)
+	req.Equal("prometheus", spoke.Spec.DataSource)
+
+	// edit through v1alpha1
+	spoke.Spec.Title = "Nginx"
+	spoke.Spec.Panels[1].Targets[0].Expression = "y"
+	spoke.Spec.Panels[1].Graph.Stack = true
+	spoke.Spec.Templatings[0].Query = "label_values(pod)"
+	spoke.Spec.Panels = append(spoke.Spec.Panels, Panel{PanelMeta: PanelMeta{Id: 3, Type: "singlestat", Title: "Up"}})
+
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.ConvertTo(&actual))
+	req.NotContains(actual.Annotations, PreservedSpecAnnotation)
+
+	spec := actual.Spec
+	req.Equal("Nginx", spec.Title)
+	req.Equal([]string{"nginx"}, spec.Tags)
+	req.Equal("utc", spec.Timezone)
+	req.Len(spec.Panels, 3)
+	req.Equal(hub.Spec.Panels[0], spec.Panels[0])
+	req.Equal("y", spec.Panels[1].Targets[0].Expression)
+	req.Equal(hub.Spec.Panels[1].Legend, spec.Panels[1].Legend)
+	req.Equal(&description, spec.Panels[1].Description)
+	req.True(spec.Panels[1].Stack)
+	req.Equal("time", spec.Panels[1].Xaxis.Format)
+	req.Equal("Up", spec.Panels[2].Title)
+	req.Equal(&datasource, spec.Panels[2].Datasource)
+	req.True(spec.Templatings[0].QueryVariable.Multi)
+	req.Equal("label_values(pod)", spec.Templatings[0].QueryVariable.Query)
+	req.Equal(hub.Spec.Templatings[1], spec.Templatings[1])
+}
+
+func TestRestoreClearedSpec(t *testing.T) {
+	req := require.New(t)
+
+	hub := v1alpha2.Dashboard{Spec: v1alpha2.DashboardSpec{Title: "Overview", Tags: []string{"nginx"}, Timezone: "utc"}}
+
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Contains(spoke.Annotations, PreservedSpecAnnotation)
+
+	// the stashed spec is kept in the metadata, while the fields only v1alpha2 has are cleared
+	var cleared v1alpha2.Dashboard
+	req.NoError(spoke.ConvertTo(&cleared))
+	req.Equal([]string{"nginx"}, cleared.Spec.Tags)
+	cleared.Annotations = spoke.Annotations
+	cleared.Spec.Tags = nil
+	cleared.Spec.Timezone = ""
+
+	req.NoError(spoke.ConvertFrom(&cleared))
+	req.NotContains(spoke.Annotations, PreservedSpecAnnotation)
+
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.ConvertTo(&actual))
+	req.Empty(actual.Spec.Tags)
+	req.Empty(actual.Spec.Timezone)
+	req.Equal("Overview", actual.Spec.Title)
+}
+
+func TestRestoreRows(t *testing.T) {
+	req := require.New(t)
+
+	hub := v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Panels: []*v1alpha2panels.Panel{{
+				CommonPanel: v1alpha2panels.CommonPanel{Id: 1, Type: "row", Title: "Overview"},
+				RowPanel: &v1alpha2panels.RowPanel{Collapsed: true, Panels: []*v1alpha2panels.Panel{
+					{CommonPanel: v1alpha2panels.CommonPanel{Id: 2, Type: "singlestat", Title: "Up"}},
+				}},
+			}},
+		},
+	}
+
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Len(spoke.Spec.Panels, 2)
+	req.Equal("Up", spoke.Spec.Panels[1].Title)
+
+	spoke.Spec.Panels[1].Title = "Targets up"
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.DeepCopy().ConvertTo(&actual))
+	req.Len(actual.Spec.Panels, 1)
+	req.True(actual.Spec.Panels[0].Collapsed)
+	req.Equal("Targets up", actual.Spec.Panels[0].RowPanel.Panels[0].Title)
+
+	// panels added through v1alpha1 can't be placed in rows
+	spoke.Spec.Panels = append(spoke.Spec.Panels, Panel{PanelMeta: PanelMeta{Id: 3, Type: "graph"}})
+	req.NoError(spoke.ConvertTo(&actual))
+	req.Len(actual.Spec.Panels, 3)
+	req.True(actual.Spec.Panels[0].Collapsed)
+	req.Empty(actual.Spec.Panels[0].RowPanel.Panels)
+}
+
+func TestConvertTimeSeriesToGraph(t *testing.T) {
+	req := require.New(t)
+
+	decimals := int64(1)
+	hub := v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Panels: []*v1alpha2panels.Panel{{
+				CommonPanel: v1alpha2panels.CommonPanel{Id: 1, Type: "timeseries", Title: "Requests", Format: "reqps", Decimals: &decimals},
+				TimeSeriesPanel: &v1alpha2panels.TimeSeriesPanel{
+					DrawStyle:         v1alpha2panels.DrawStyleBars,
+					StackingMode:      v1alpha2panels.StackingPercent,
+					LineInterpolation: "smooth",
+				},
+			}},
+		},
+	}
+
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Equal(Panel{
+		PanelMeta: PanelMeta{Id: 1, Type: PanelGraph, Title: "Requests"},
+		Graph: &panels.Graph{
+			Bars:  true,
+			Stack: true,
+			Yaxes: []panels.Yaxis{{Decimals: 1, Format: "reqps"}},
+		},
+	}, spoke.Spec.Panels[0])
+
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.DeepCopy().ConvertTo(&actual))
+	req.Equal(hub.Spec, actual.Spec)
+
+	// changes made to the graph apply to the time series panel
+	spoke.Spec.Panels[0].Graph.Bars = false
+	spoke.Spec.Panels[0].Graph.Lines = true
+	spoke.Spec.Panels[0].Graph.Yaxes[0].Format = "short"
+	req.NoError(spoke.ConvertTo(&actual))
+	panel := actual.Spec.Panels[0]
+	req.Equal("timeseries", panel.Type)
+	req.Equal(v1alpha2panels.DrawStyleLine, panel.DrawStyle)
+	req.Equal(v1alpha2panels.StackingPercent, panel.StackingMode)
+	req.Equal("smooth", panel.LineInterpolation)
+	req.Equal("short", panel.Format)
+}
+
+func TestConvertHeatmapToGraph(t *testing.T) {
+	req := require.New(t)
+
+	hub := v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Panels: []*v1alpha2panels.Panel{{
+				CommonPanel: v1alpha2panels.CommonPanel{Id: 1, Type: "heatmap", Title: "Latency", Targets: []v1alpha2panels.Target{
+					{RefID: 1, Expression: "sum by (le) (rate(http_request_duration_seconds_bucket[5m]))", Format: "heatmap"},
+				}},
+				HeatmapPanel: &v1alpha2panels.HeatmapPanel{
+					DataFormat:      v1alpha2panels.HeatmapDataFormatTSBuckets,
+					HideZeroBuckets: true,
+					YAxis:           v1alpha2panels.Axis{Format: "s", Decimals: 2},
+				},
+			}},
+		},
+	}
+
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Equal(Panel{
+		PanelMeta: PanelMeta{Id: 1, Type: PanelGraph, Title: "Latency"},
+		Targets:   []panels.Target{{RefID: 1, Expression: "sum by (le) (rate(http_request_duration_seconds_bucket[5m]))"}},
+		Graph: &panels.Graph{
+			Lines: true,
+			Yaxes: []panels.Yaxis{{Decimals: 2, Format: "s"}},
+		},
+	}, spoke.Spec.Panels[0])
+
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.DeepCopy().ConvertTo(&actual))
+	req.Equal(hub.Spec, actual.Spec)
+
+	// only the y-axis of the graph applies to the heatmap
+	spoke.Spec.Panels[0].Graph.Bars = true
+	spoke.Spec.Panels[0].Graph.Yaxes[0].Format = "ms"
+	req.NoError(spoke.ConvertTo(&actual))
+	panel := actual.Spec.Panels[0]
+	req.Equal("heatmap", panel.Type)
+	req.Nil(panel.GraphPanel)
+	req.Equal("ms", panel.HeatmapPanel.YAxis.Format)
+	req.Equal(v1alpha2panels.HeatmapDataFormatTSBuckets, panel.DataFormat)
+	req.True(panel.HideZeroBuckets)
+}
+
+func TestConvertTargetOptions(t *testing.T) {
+	req := require.New(t)
+
+	thanos := datasources.DatasourceRef{Name: "thanos"}
+	hub := v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Panels: []*v1alpha2panels.Panel{{
+				CommonPanel: v1alpha2panels.CommonPanel{Id: 1, Type: "graph", Title: "Requests", Targets: []v1alpha2panels.Target{
+					{RefID: 1, RefName: "A", Expression: "x", Range: true, Exemplar: true, Interval: "$interval", IntervalFactor: 2},
+					{RefName: "C", Expression: "y", Instant: true, Format: v1alpha2panels.TargetFormatTable, Hide: true, Datasource: &thanos},
+				}},
+			}},
+		},
+	}
+
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Equal([]panels.Target{
+		{RefID: 1, Expression: "x"},
+		{RefID: 3, Expression: "y"},
+	}, spoke.Spec.Panels[0].Targets)
+
+	// edit through v1alpha1
+	spoke.Spec.Panels[0].Targets[1].Expression = "z"
+
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.ConvertTo(&actual))
+	targets := actual.Spec.Panels[0].Targets
+	req.Equal(hub.Spec.Panels[0].Targets[0], targets[0])
+	req.Equal("z", targets[1].Expression)
+	req.Equal("C", targets[1].RefName)
+	req.True(targets[1].Instant)
+	req.True(targets[1].Hide)
+	req.Equal(v1alpha2panels.TargetFormatTable, targets[1].Format)
+	req.Equal(&thanos, targets[1].Datasource)
+}
+
+func TestConvertDatasources(t *testing.T) {
+	req := require.New(t)
+
+	hub := v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Inputs: []datasources.Input{{Name: "DS_PROMETHEUS", PluginID: "prometheus", Datasource: &datasources.DatasourceRef{Name: "prometheus"}}},
+			Panels: []*v1alpha2panels.Panel{
+				{CommonPanel: v1alpha2panels.CommonPanel{Id: 1, Type: "text", Title: "Notes"}},
+				{CommonPanel: v1alpha2panels.CommonPanel{Id: 2, Type: "graph", Title: "Requests", Datasource: datasources.Mixed(), Targets: []v1alpha2panels.Target{
+					{RefID: 1, Expression: "x", Datasource: &datasources.DatasourceRef{Type: "prometheus", UID: "${DS_PROMETHEUS}"}},
+					{RefID: 2, Expression: "y", Datasource: &datasources.DatasourceRef{Type: "prometheus", UID: "thanos"}},
+				}}},
+			},
+		},
+	}
+
+	// v1alpha1 takes the first datasource, once the inputs are resolved
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Equal("prometheus", spoke.Spec.DataSource)
+
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.ConvertTo(&actual))
+	req.Equal(hub.Spec, actual.Spec)
+
+	// a datasource set through v1alpha1 replaces the ones of the panels
+	spoke.Spec.DataSource = "thanos"
+	req.NoError(spoke.ConvertTo(&actual))
+	req.Equal(hub.Spec.Inputs, actual.Spec.Inputs)
+	for _, panel := range actual.Spec.Panels {
+		req.Equal(&datasources.DatasourceRef{Name: "thanos"}, panel.Datasource)
+	}
+}
+
+func TestConvertTimeOverrides(t *testing.T) {
+	req := require.New(t)
+
+	hub := v1alpha2.Dashboard{
+		Spec: v1alpha2.DashboardSpec{
+			Panels: []*v1alpha2panels.Panel{
+				{CommonPanel: v1alpha2panels.CommonPanel{Id: 1, Type: "graph", Title: "Requests", TimeFrom: "24h", TimeShift: "1w", HideTimeOverride: true,
+					Targets: []v1alpha2panels.Target{
+						{RefID: 1, Expression: "x"},
+						{RefID: 2, Expression: "x", TimeShift: "1d"},
+					}}},
+			},
+		},
+	}
+
+	// v1alpha1 has no time overrides, they are kept in the annotation
+	var spoke Dashboard
+	req.NoError(spoke.ConvertFrom(&hub))
+	req.Contains(spoke.Annotations, PreservedSpecAnnotation)
+
+	// edit through v1alpha1
+	spoke.Spec.Panels[0].Title = "Requests last week"
+	spoke.Spec.Panels[0].Targets[1].Expression = "y"
+
+	var actual v1alpha2.Dashboard
+	req.NoError(spoke.ConvertTo(&actual))
+	panel := actual.Spec.Panels[0]
+	req.Equal("Requests last week", panel.Title)
+	req.Equal("24h", panel.TimeFrom)
+	req.Equal("1w", panel.TimeShift)
+	req.True(panel.HideTimeOverride)
+	req.Equal(v1alpha2panels.Target{RefID: 2, Expression: "y", TimeShift: "1d"}, panel.Targets[1])
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"kubesphere.io/monitoring-dashboard/api/v1alpha1/panels"
+)
+
+// probePanel reads the fields shared by all panel types
+type probePanel struct {
+	PanelMeta `json:",inline"`
+	Targets   []panels.Target `json:"targets,omitempty"`
+}
+
+// UnmarshalJSON reads the type specific fields, which are inlined next to the panel metadata
+func (p *Panel) UnmarshalJSON(b []byte) error {
+	var probe probePanel
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return err
+	}
+	p.PanelMeta = probe.PanelMeta
+	p.Targets = probe.Targets
+
+	switch p.Type {
+	case PanelGraph:
+		var graph panels.Graph
+		if err := json.Unmarshal(b, &graph); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(graph, panels.Graph{}) {
+			p.Graph = &graph
+		}
+	case PanelSingleStat:
+		var singlestat panels.SingleStat
+		if err := json.Unmarshal(b, &singlestat); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(singlestat, panels.SingleStat{}) {
+			p.SingleStat = &singlestat
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes the type specific fields inline with the panel metadata
+func (p Panel) MarshalJSON() ([]byte, error) {
+	probe := probePanel{p.PanelMeta, p.Targets}
+
+	switch {
+	case p.Type == PanelGraph && p.Graph != nil:
+		return json.Marshal(struct {
+			probePanel
+			*panels.Graph
+		}{probe, p.Graph})
+	case p.Type == PanelSingleStat && p.SingleStat != nil:
+		return json.Marshal(struct {
+			probePanel
+			*panels.SingleStat
+		}{probe, p.SingleStat})
+	}
+	return json.Marshal(probe)
+}
@@ -39,11 +39,10 @@ type DashboardSpec struct {
 
 // Time ranges of the metrics for display
 type Time struct {
-	// Start time in the format of `^now([+-][0-9]+[smhdwMy])?$`, eg. `now-1M`.
-	// It denotes the end time is set to the last month since now.
+	// Start time relative to now such as `now-1M` or `now-1d/d`, a date and time, or a unix timestamp in milliseconds.
+	// `now-1M` denotes the start time is set to the last month since now.
 	From string `json:"from,omitempty"`
-	// End time in the format of `^now([+-][0-9]+[smhdwMy])?$`, eg. `now-1M`.
-	// It denotes the start time is set to the last month since now.
+	// End time relative to now such as `now` or `now-1d/d`, a date and time, or a unix timestamp in milliseconds.
 	To string `json:"to,omitempty"`
 }
 
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	datasources "kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	panels "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	templatings "kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+)
+
+// inputNamePattern matches the names usable as ${NAME} in a datasource reference
+var inputNamePattern = regexp.MustCompile(`^\w+$`)
+
+// ResolveDatasources replaces the references to the inputs of the dashboard with the datasource
+// chosen for them, or with the default datasource of their type when none is
+func (in *DashboardSpec) ResolveDatasources() {
+	inputs := make(map[string]datasources.Input, len(in.Inputs))
+	for _, input := range in.Inputs {
+		inputs[input.Name] = input
+	}
+
+	resolve := func(ref *datasources.DatasourceRef) *datasources.DatasourceRef {
+		input, ok := inputs[ref.Variable()]
+		if ref == nil || !ok {
+			return ref
+		}
+		if input.Datasource == nil {
+			if input.PluginID == "" {
+				return nil
+			}
+			return &datasources.DatasourceRef{Type: input.PluginID}
+		}
+		resolved := input.Datasource.DeepCopy()
+		if resolved.Type == "" {
+			resolved.Type = input.PluginID
+		}
+		return resolved
+	}
+
+	for _, panel := range panels.Flatten(in.Panels) {
+		panel.Datasource = resolve(panel.Datasource)
+		for i := range panel.Targets {
+			panel.Targets[i].Datasource = resolve(panel.Targets[i].Datasource)
+		}
+	}
+	for i := range in.Templatings {
+		in.Templatings[i].Datasource = resolve(in.Templatings[i].Datasource)
+	}
+}
+
+// validateDatasources checks the inputs, that the references made of a variable refer to an input or
+// to a datasource variable, and that the targets of the panels using the mixed datasource set their own
+func (in *DashboardSpec) validateDatasources(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	declared := map[string]bool{}
+	for i, input := range in.Inputs {
+		inputPath := path.Child("inputs").Index(i)
+		switch {
+		case input.Name == "":
+			errs = append(errs, field.Required(inputPath.Child("name"), ""))
+		case !inputNamePattern.MatchString(input.Name):
+			errs = append(errs, field.Invalid(inputPath.Child("name"), input.Name, "must match "+inputNamePattern.String()))
+		case declared[input.Name]:
+			errs = append(errs, field.Duplicate(inputPath.Child("name"), input.Name))
+		}
+		declared[input.Name] = true
+
+		if ref := input.Datasource; ref.Variable() != "" || ref.IsMixed() {
+			errs = append(errs, field.Invalid(inputPath.Child("datasource"), ref.String(), "must be a datasource"))
+		}
+	}
+	for _, variable := range in.Templatings {
+		if variable.Type == templatings.TypeDatasource {
+			declared[variable.Name] = true
+		}
+	}
+
+	errs = append(errs, validatePanelDatasources(in.Panels, declared, path.Child("panels"))...)
+
+	for i, variable := range in.Templatings {
+		errs = append(errs, validateDatasource(variable.Datasource, declared, path.Child("templatings").Index(i).Child("datasource"))...)
+		if variable.Datasource.IsMixed() {
+			errs = append(errs, field.Invalid(path.Child("templatings").Index(i).Child("datasource"), variable.Datasource.String(),
+				"only panels can use the mixed datasource"))
+		}
+	}
+	return errs
+}
+
+// validatePanelDatasources checks the datasources of the panels and their targets, and of the panels nested in rows
+func validatePanelDatasources(pls []*panels.Panel, declared map[string]bool, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, panel := range pls {
+		if panel == nil {
+			continue
+		}
+		panelPath := path.Index(i)
+		errs = append(errs, validateDatasource(panel.Datasource, declared, panelPath.Child("datasource"))...)
+
+		mixed := panel.Datasource.IsMixed()
+		for j, target := range panel.Targets {
+			targetPath := panelPath.Child("targets").Index(j).Child("datasource")
+			switch {
+			case target.Datasource.IsMixed():
+				errs = append(errs, field.Invalid(targetPath, target.Datasource.String(), "only panels can use the mixed datasource"))
+			case mixed && target.Datasource == nil:
+				errs = append(errs, field.Required(targetPath, "the targets of a panel using the mixed datasource need their own"))
+			default:
+				errs = append(errs, validateDatasource(target.Datasource, declared, targetPath)...)
+			}
+		}
+		if panel.RowPanel != nil {
+			errs = append(errs, validatePanelDatasources(panel.RowPanel.Panels, declared, panelPath.Child("panels"))...)
+		}
+	}
+	return errs
+}
+
+// validateDatasource checks that a reference made of a variable refers to an input or a datasource variable
+func validateDatasource(ref *datasources.DatasourceRef, declared map[string]bool, path *field.Path) field.ErrorList {
+	if name := ref.Variable(); name != "" && !declared[name] {
+		return field.ErrorList{field.Invalid(path, ref.String(),
+			"must refer to one of the inputs or to a datasource variable, $"+name+" is neither")}
+	}
+	return nil
+}
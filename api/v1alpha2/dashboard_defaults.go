@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"strings"
+
+	panels "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	time "kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+)
+
+// Defaults applied to a dashboard spec
+const (
+	DefaultTimeFrom = "now-1h"
+	DefaultTimeTo   = "now"
+)
+
+// Default fills in the fields left out of hand-written manifests: panel IDs,
+// target refIds, graph colors and the time range.
+// Target steps are deliberately left empty: the step of a target is the lower bound
+// of $__interval, which otherwise follows the time range, the panel width and the scrape
+// interval, so any default step would pin the resolution of every query.
+// The browser and utc timezones are written in lower case, as grafana does,
+// and the references to the inputs are replaced with their datasource.
+func (in *DashboardSpec) Default() {
+	if in.Time.From == "" {
+		in.Time.From = DefaultTimeFrom
+	}
+	if in.Time.To == "" {
+		in.Time.To = DefaultTimeTo
+	}
+	switch tz := strings.ToLower(in.Timezone); tz {
+	case time.TimezoneBrowser, time.TimezoneUTC:
+		in.Timezone = tz
+	}
+
+	all := panels.Flatten(in.Panels)
+
+	var maxID int64
+	for _, panel := range all {
+		if panel.Id > maxID {
+			maxID = panel.Id
+		}
+	}
+
+	for _, panel := range all {
+		if panel.Id == 0 {
+			maxID++
+			panel.Id = maxID
+		}
+		if (panel.Type == panels.TypeGraph || panel.Type == panels.TypeTimeSeries) && len(panel.Colors) == 0 {
+			panel.Colors = panels.DefaultColors()
+		}
+		defaultTargets(panel.Targets)
+	}
+
+	in.ResolveDatasources()
+}
+
+// defaultTargets numbers the targets without a refId after the highest one in use
+func defaultTargets(targets []panels.Target) {
+	var maxRefID int64
+	for _, target := range targets {
+		if target.RefID > maxRefID {
+			maxRefID = target.RefID
+		}
+	}
+
+	for i := range targets {
+		if targets[i].RefID == 0 {
+			maxRefID++
+			targets[i].RefID = maxRefID
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	links "kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+	panels "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// validateLinks checks that the links of the dashboard and of its panels, including the panels of rows,
+// have what they point at: a URL, the name of a dashboard or tags
+func (in *DashboardSpec) validateLinks(path *field.Path) field.ErrorList {
+	errs := validateLinks(in.Links, path.Child("links"))
+	return append(errs, validatePanelLinks(in.Panels, path.Child("panels"))...)
+}
+
+func validatePanelLinks(pls []*panels.Panel, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, panel := range pls {
+		if panel == nil {
+			continue
+		}
+		errs = append(errs, validateLinks(panel.Links, path.Index(i).Child("links"))...)
+		if panel.RowPanel != nil {
+			errs = append(errs, validatePanelLinks(panel.RowPanel.Panels, path.Index(i).Child("panels"))...)
+		}
+	}
+	return errs
+}
+
+func validateLinks(lks []links.Link, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, link := range lks {
+		linkPath := path.Index(i)
+		switch link.Type {
+		case links.TypeLink, "":
+			if link.URL == "" {
+				errs = append(errs, field.Required(linkPath.Child("url"), "a link needs a url"))
+			}
+		case links.TypeDashboard:
+			if link.Dashboard == "" {
+				errs = append(errs, field.Required(linkPath.Child("dashboard"), "a link to a dashboard needs its name"))
+			}
+		case links.TypeDashboards:
+			if len(link.Tags) == 0 {
+				errs = append(errs, field.Required(linkPath.Child("tags"), "a link to dashboards needs their tags"))
+			}
+		}
+	}
+	return errs
+}
+
+// linkVariables lists the variables used in the URL and parameters of the links
+func linkVariables(lks []links.Link) []string {
+	var parts []string
+	for _, link := range lks {
+		parts = append(parts, link.URL, link.Params, link.Dashboard)
+	}
+	return usedVariables(strings.Join(parts, " "))
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Placeholders substituted for variables before an expression is parsed.
+// A duration is used inside range selectors and after offset, anything else
+// is replaced by a name which is valid both as a metric and as a label value.
+const (
+	placeholderValue    = "placeholder"
+	placeholderDuration = "5m"
+)
+
+var (
+	offsetPattern      = regexp.MustCompile(`\boffset\s*-?\s*$`)
+	labelValuesPattern = regexp.MustCompile(`^\s*label_values\(\s*(?:(.*?)\s*,\s*)?\w+\s*\)\s*$`)
+	queryResultPattern = regexp.MustCompile(`^\s*query_result\((.*)\)\s*$`)
+	metadataPattern    = regexp.MustCompile(`^\s*(?:label_names\(\s*\)|metrics\(.*\))\s*$`)
+)
+
+// ParseExpression checks that expr is a valid PromQL expression once its variables are substituted
+func ParseExpression(expr string) error {
+	_, err := parser.ParseExpr(substituteVariables(expr))
+	return err
+}
+
+// ParseVariableQuery checks the query of a templating variable.
+// Besides plain PromQL, the Grafana functions label_values, query_result,
+// label_names and metrics are accepted.
+func ParseVariableQuery(query string) error {
+	if metadataPattern.MatchString(query) {
+		return nil
+	}
+	if m := labelValuesPattern.FindStringSubmatch(query); m != nil {
+		if m[1] == "" {
+			return nil
+		}
+		return ParseExpression(m[1])
+	}
+	if m := queryResultPattern.FindStringSubmatch(query); m != nil {
+		return ParseExpression(m[1])
+	}
+	return ParseExpression(query)
+}
+
+// substituteVariables replaces every variable in expr by a placeholder fitting its position
+func substituteVariables(expr string) string {
+	matches := variablePattern.FindAllStringIndex(expr, -1)
+	if len(matches) == 0 {
+		return expr
+	}
+
+	var b strings.Builder
+	var quote byte
+	depth := 0
+	next := 0
+	for i := 0; i < len(expr); {
+		if next < len(matches) && matches[next][0] == i {
+			if quote == 0 && (depth > 0 || offsetPattern.MatchString(b.String())) {
+				b.WriteString(placeholderDuration)
+			} else {
+				b.WriteString(placeholderValue)
+			}
+			i = matches[next][1]
+			next++
+			continue
+		}
+
+		c := expr[i]
+		switch {
+		case quote != 0 && c == '\\' && quote != '`' && i+1 < len(expr):
+			b.WriteByte(c)
+			i++
+			c = expr[i]
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
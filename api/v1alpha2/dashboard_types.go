@@ -17,6 +17,8 @@ package v1alpha2
 
 import (
 	ants "kubesphere.io/monitoring-dashboard/api/v1alpha2/annotations"
+	datasources "kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	links "kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
 	panels "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,6 +44,52 @@ type DashboardSpec struct {
 	Panels []*panels.Panel `json:"panels,omitempty"`
 	// // Templating variables
 	Templatings []templatings.TemplateVar `json:"templatings,omitempty"`
+	// Datasources chosen when the dashboard is applied, which panels, targets and variables refer to as ${NAME}
+	Inputs []datasources.Input `json:"inputs,omitempty"`
+	// Links to other dashboards or URLs, shown at the top of the dashboard
+	Links []links.Link `json:"links,omitempty"`
+}
+
+// Condition types reported in DashboardStatus
+const (
+	// DashboardValid is true when the spec passes all structural checks
+	DashboardValid = "Valid"
+	// DashboardSynced is true when the status reflects the latest spec generation
+	DashboardSynced = "Synced"
+	// DashboardQueriesHealthy is true when every target carries a usable query
+	DashboardQueriesHealthy = "QueriesHealthy"
+)
+
+// DashboardStatus defines the observed state of Dashboard
+type DashboardStatus struct {
+	// The most recent generation observed by the dashboard controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Latest observations of the dashboard: Valid, Synced and QueriesHealthy
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Number of panels, including rows
+	PanelCount int32 `json:"panelCount,omitempty"`
+	// Number of queries over all panels
+	TargetCount int32 `json:"targetCount,omitempty"`
+	// Number of templating variables
+	VariableCount int32 `json:"variableCount,omitempty"`
+	// Problems found in individual panels
+	Problems []PanelProblem `json:"problems,omitempty"`
+}
+
+// PanelProblem describes why a panel is broken
+type PanelProblem struct {
+	// Panel ID
+	PanelID int64 `json:"panelId,omitempty"`
+	// Name of the panel
+	PanelTitle string `json:"panelTitle,omitempty"`
+	// Reference ID of the offending target, if any
+	RefID int64 `json:"refId,omitempty"`
+	// A machine-readable reason in CamelCase
+	Reason string `json:"reason"`
+	// A human-readable description of the problem
+	Message string `json:"message"`
 }
 
 // +kubebuilder:object:root=true
@@ -53,7 +101,8 @@ type Dashboard struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec DashboardSpec `json:"spec,omitempty"`
+	Spec   DashboardSpec   `json:"spec,omitempty"`
+	Status DashboardStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -67,6 +116,7 @@ type DashboardList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:subresource:status
 // +kubebuilder:storageversion
 
 // ClusterDashboard is the Schema for the culsterdashboards API
@@ -74,7 +124,8 @@ type ClusterDashboard struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec DashboardSpec `json:"spec,omitempty"`
+	Spec   DashboardSpec   `json:"spec,omitempty"`
+	Status DashboardStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -5,11 +5,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
 	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
 )
 
 var (
-	datasource = "prometheus"
+	datasource = datasources.DatasourceRef{Name: "prometheus"}
 
 	js = `
 	{
@@ -91,3 +94,248 @@ func TestDashboardSpecSerde(t *testing.T) {
 
 	require.EqualValues(t, expected, actual)
 }
+
+func TestRowPanelSerde(t *testing.T) {
+	req := require.New(t)
+
+	row := `
+	{
+		"id": 1,
+		"title": "Overview",
+		"type": "row",
+		"collapsed": true,
+		"repeat": "namespace",
+		"panels": [
+			{
+				"id": 2,
+				"title": "Connections",
+				"type": "graph",
+				"lines": true,
+				"xaxis": {},
+				"targets": [{
+					"expr": "vector(1)"
+				}]
+			}
+		]
+	}
+	`
+
+	var panel panels.Panel
+	req.NoError(json.Unmarshal([]byte(row), &panel))
+	req.Equal(&panels.Panel{
+		CommonPanel: panels.CommonPanel{Id: 1, Title: "Overview", Type: "row", Repeat: "namespace"},
+		RowPanel: &panels.RowPanel{
+			Collapsed: true,
+			Panels: []*panels.Panel{{
+				CommonPanel: panels.CommonPanel{Id: 2, Title: "Connections", Type: "graph", Targets: []panels.Target{{Expression: "vector(1)"}}},
+				GraphPanel:  &panels.GraphPanel{Lines: true},
+			}},
+		},
+	}, &panel)
+
+	out, err := json.Marshal(&panel)
+	req.NoError(err)
+	req.JSONEq(row, string(out))
+
+	spec := DashboardSpec{Panels: []*panels.Panel{&panel}}
+	req.Len(panels.Flatten(spec.Panels), 2)
+}
+
+func TestStatAndGaugePanelSerde(t *testing.T) {
+	req := require.New(t)
+
+	for _, js := range []string{
+		`{"id": 1, "type": "stat", "reduceOptions": {"calcs": ["lastNotNull"]}, "colorMode": "background", "graphMode": "none"}`,
+		`{"id": 2, "type": "gauge", "reduceOptions": {"values": true}, "showThresholdMarkers": true}`,
+	} {
+		var panel panels.Panel
+		req.NoError(json.Unmarshal([]byte(js), &panel))
+		req.NotNil(panel.ReduceOptions)
+		req.True(panel.StatPanel != nil || panel.GaugePanel != nil)
+
+		out, err := json.Marshal(&panel)
+		req.NoError(err)
+		req.JSONEq(js, string(out))
+	}
+}
+
+func TestHeatmapPanelSerde(t *testing.T) {
+	req := require.New(t)
+
+	js := `{"id": 1, "type": "heatmap", "dataFormat": "tsbuckets", "color": {"mode": "opacity", "cardColor": "#b4ff00"},
+		"yAxis": {"format": "s"}, "yBucketBound": "upper", "tooltipHistogram": true}`
+	var panel panels.Panel
+	req.NoError(json.Unmarshal([]byte(js), &panel))
+	req.NotNil(panel.HeatmapPanel)
+	req.Equal("s", panel.HeatmapPanel.YAxis.Format)
+	req.Nil(panel.GraphPanel)
+
+	out, err := json.Marshal(&panel)
+	req.NoError(err)
+	req.JSONEq(js, string(out))
+}
+
+func TestUnknownPanelTypeSerde(t *testing.T) {
+	req := require.New(t)
+
+	js := `{"id": 1, "type": "piechart", "title": "Share", "pieType": "donut", "options": {"displayLabels": ["percent"]}}`
+	var panel panels.Panel
+	req.NoError(json.Unmarshal([]byte(js), &panel))
+	req.Equal("Share", panel.Title)
+	req.JSONEq(`{"pieType": "donut", "options": {"displayLabels": ["percent"]}}`, string(panel.Custom))
+
+	options, err := panel.Options()
+	req.NoError(err)
+	req.Nil(options)
+
+	out, err := json.Marshal(&panel)
+	req.NoError(err)
+	req.JSONEq(js, string(out))
+}
+
+type clockPanel struct {
+	Mode     string `json:"mode,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+func TestRegisteredPanelType(t *testing.T) {
+	req := require.New(t)
+	panels.RegisterPanelType("test-clock", func() interface{} { return &clockPanel{} })
+	req.True(panels.IsKnownType("test-clock"))
+	req.Panics(func() {
+		panels.RegisterPanelType("test-clock", func() interface{} { return &clockPanel{} })
+	})
+
+	js := `{"id": 1, "type": "test-clock", "mode": "countdown", "timezone": "UTC"}`
+	var panel panels.Panel
+	req.NoError(json.Unmarshal([]byte(js), &panel))
+	options, err := panel.Options()
+	req.NoError(err)
+	req.Equal(&clockPanel{Mode: "countdown", Timezone: "UTC"}, options)
+
+	req.Error(panel.SetOptions(&panels.StatPanel{}))
+	req.NoError(panel.SetOptions(&clockPanel{Mode: "time"}))
+	out, err := json.Marshal(&panel)
+	req.NoError(err)
+	req.JSONEq(`{"id": 1, "type": "test-clock", "mode": "time"}`, string(out))
+
+	req.Error(json.Unmarshal([]byte(`{"type": "test-clock", "mode": 1}`), &panel))
+}
+
+func TestBuiltinPanelOptions(t *testing.T) {
+	req := require.New(t)
+
+	panel := panels.Panel{CommonPanel: panels.CommonPanel{Type: panels.TypeText}}
+	req.NoError(panel.SetOptions(&panels.TextPanel{Mode: "markdown", Content: "# Hi"}))
+	req.Equal("markdown", panel.TextPanel.Mode)
+
+	options, err := panel.Options()
+	req.NoError(err)
+	req.Equal(panel.TextPanel, options)
+	req.Empty(panel.Custom)
+}
+
+func TestLegendMigration(t *testing.T) {
+	req := require.New(t)
+
+	var panel panels.Panel
+	req.NoError(json.Unmarshal([]byte(`{"type": "graph", "legend": ["as_table", "to_the_right", "avg", "current", "no_zero_series", "typo"]}`), &panel))
+	req.Equal(&panels.Legend{
+		DisplayMode: panels.LegendDisplayTable,
+		Placement:   panels.LegendPlacementRight,
+		Calcs:       []panels.Calc{panels.CalcMean, panels.CalcLastNotNull},
+		HideZero:    true,
+	}, panel.Legend)
+
+	out, err := json.Marshal(&panel)
+	req.NoError(err)
+	req.JSONEq(`{"type": "graph", "legend": {"displayMode": "table", "placement": "right", "calcs": ["mean", "lastNotNull"], "hideZero": true}}`, string(out))
+
+	req.Equal(panels.LegendDisplayHidden, panels.LegendFromFlags([]string{"as_table", "hide"}).DisplayMode)
+	req.Nil(panels.LegendFromFlags(nil))
+}
+
+func TestTemplateVarSerde(t *testing.T) {
+	req := require.New(t)
+
+	for _, js := range []string{
+		`{"name": "namespace", "type": "query", "datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"}, "query": "label_values(namespace)", "multi": true}`,
+		`{"name": "env", "type": "custom", "options": [{"text": "prod", "value": "prod", "selected": true}], "includeAll": true}`,
+		`{"name": "step", "type": "interval", "intervals": ["1m", "1h"], "auto": true, "auto_count": 30}`,
+		`{"name": "cluster", "type": "constant", "value": "host", "hide": 2}`,
+		`{"name": "ds", "type": "datasource", "pluginId": "prometheus", "regex": "/^prod/"}`,
+		`{"name": "filter", "type": "textbox", "default": ".*"}`,
+		`{"name": "labels", "type": "adhoc", "filters": [{"key": "job", "operator": "=~", "value": "node.*"}]}`,
+	} {
+		var variable templatings.TemplateVar
+		req.NoError(json.Unmarshal([]byte(js), &variable))
+		req.Empty(variable.Validate(field.NewPath("variable")), js)
+
+		out, err := json.Marshal(&variable)
+		req.NoError(err)
+		req.JSONEq(js, string(out))
+	}
+}
+
+func TestTemplateVarMigration(t *testing.T) {
+	req := require.New(t)
+
+	var variables []templatings.TemplateVar
+	req.NoError(json.Unmarshal([]byte(`[
+		{"name": "namespace", "query": "label_values(namespace)"},
+		{"name": "step", "type": "interval", "query": "1m, 5m"},
+		{"name": "env", "type": "custom", "query": "prod,staging"},
+		{"name": "cluster", "type": "constant", "query": "host"}
+	]`), &variables))
+
+	req.Equal(templatings.TypeQuery, variables[0].Type)
+	req.Equal("label_values(namespace)", variables[0].QueryVariable.Query)
+	req.Equal([]string{"1m", "5m"}, variables[1].Intervals)
+	req.Equal([]templatings.Option{{Text: "prod", Value: "prod"}, {Text: "staging", Value: "staging"}}, variables[2].Options)
+	req.Equal("host", variables[3].ConstantVariable.Value)
+}
+
+func TestValidateTemplateVar(t *testing.T) {
+	req := require.New(t)
+
+	var variables []templatings.TemplateVar
+	req.NoError(json.Unmarshal([]byte(`[
+		{"name": "step", "type": "interval", "intervals": ["1m", "fast"], "auto_count": 0},
+		{"name": "env", "type": "custom"},
+		{"name": "my-var", "type": "textbox"},
+		{"name": "ds", "type": "datasources"},
+		{"name": "labels", "type": "adhoc", "filters": [{"key": "", "operator": "=="}]}
+	]`), &variables))
+
+	var errs field.ErrorList
+	for i := range variables {
+		errs = append(errs, variables[i].Validate(field.NewPath("templatings").Index(i))...)
+	}
+
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	req.Equal([]string{
+		"templatings[0].intervals[1]",
+		"templatings[0].auto_count",
+		"templatings[1].options",
+		"templatings[2].name",
+		"templatings[3].type",
+		"templatings[4].filters[0].key",
+		"templatings[4].filters[0].operator",
+	}, fields)
+}
+
+func TestRefIDNames(t *testing.T) {
+	req := require.New(t)
+
+	for name, id := range map[string]int64{"A": 1, "B": 2, "Z": 26, "AA": 27, "AZ": 52, "BA": 53, "ZZ": 702, "AAA": 703} {
+		req.Equal(id, panels.RefIDFromName(name), name)
+		req.Equal(name, panels.RefNameFromID(id), id)
+	}
+	for _, name := range []string{"", "a", "A1", "cpu", "ÄB"} {
+		req.Zero(panels.RefIDFromName(name), name)
+	}
+	req.Empty(panels.RefNameFromID(0))
+}
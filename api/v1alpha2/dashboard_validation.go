@@ -0,0 +1,255 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	panels "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// Reasons of panel problems
+const (
+	ReasonMissingType        = "MissingType"
+	ReasonUnknownType        = "UnknownType"
+	ReasonDuplicatePanelID   = "DuplicatePanelID"
+	ReasonDuplicateRefID     = "DuplicateRefID"
+	ReasonEmptyExpression    = "EmptyExpression"
+	ReasonUndeclaredVariable = "UndeclaredVariable"
+	ReasonInvalidExpression  = "InvalidExpression"
+	ReasonOutOfGrid          = "OutOfGrid"
+	ReasonOverlappingPanels  = "OverlappingPanels"
+	ReasonInvalidLegend      = "InvalidLegend"
+	ReasonBrokenLink         = "BrokenLink"
+)
+
+// variablePattern matches $var, ${var}, ${var:format}, [[var]] and [[var:format]].
+// Names start with a letter or an underscore, so that regular expression backreferences such as $1 are not variables.
+var variablePattern = regexp.MustCompile(`\$([A-Za-z_]\w*)|\$\{([A-Za-z_]\w*)(?::[^}]*)?\}|\[\[([A-Za-z_]\w*)(?::[^\]]*)?\]\]`)
+
+// IsQueryProblem reports whether the problem is about a query rather than the panel itself
+func (p PanelProblem) IsQueryProblem() bool {
+	switch p.Reason {
+	case ReasonEmptyExpression, ReasonUndeclaredVariable, ReasonInvalidExpression:
+		return true
+	}
+	return false
+}
+
+// String describes the problem together with the panel and target it belongs to,
+// problems of the dashboard itself have neither panel ID nor title
+func (p PanelProblem) String() string {
+	var name string
+	switch {
+	case p.PanelTitle != "":
+		name = fmt.Sprintf("panel %q", p.PanelTitle)
+	case p.PanelID != 0:
+		name = fmt.Sprintf("panel %d", p.PanelID)
+	default:
+		name = "dashboard"
+	}
+	if p.RefID != 0 {
+		name = fmt.Sprintf("%s refId %d", name, p.RefID)
+	}
+	return name + ": " + p.Message
+}
+
+// Validate checks the panels of the spec and returns all problems found.
+// Panel IDs must be unique, refIds and refNames must be unique within a panel, the panel type
+// must be known, a legend can only be sorted by one of its calcs, expressions must
+// be valid PromQL and every variable used in an expression or a link, or repeating a panel, must be declared.
+func (in *DashboardSpec) Validate() []PanelProblem {
+	var problems []PanelProblem
+
+	declared := make(map[string]bool, len(in.Templatings))
+	for _, v := range in.Templatings {
+		declared[v.Name] = true
+	}
+
+	for _, name := range linkVariables(in.Links) {
+		if !declared[name] {
+			problems = append(problems, PanelProblem{
+				Reason:  ReasonUndeclaredVariable,
+				Message: fmt.Sprintf("variable $%s of a link is not declared in templatings", name),
+			})
+		}
+	}
+
+	ids := make(map[int64]bool, len(in.Panels))
+	for _, panel := range panels.Flatten(in.Panels) {
+		problem := func(refID int64, reason, format string, args ...interface{}) {
+			problems = append(problems, PanelProblem{
+				PanelID:    panel.Id,
+				PanelTitle: panel.Title,
+				RefID:      refID,
+				Reason:     reason,
+				Message:    fmt.Sprintf(format, args...),
+			})
+		}
+
+		switch {
+		case panel.Type == "":
+			problem(0, ReasonMissingType, "panel type is not set")
+		case !panels.IsKnownType(panel.Type):
+			problem(0, ReasonUnknownType, "unknown panel type %q", panel.Type)
+		}
+
+		if legend := panel.Legend; legend != nil && legend.SortBy != "" && !hasCalc(legend.Calcs, legend.SortBy) {
+			problem(0, ReasonInvalidLegend, "legend is sorted by %q, which is not one of its calcs", legend.SortBy)
+		}
+
+		if panel.Id != 0 {
+			if ids[panel.Id] {
+				problem(0, ReasonDuplicatePanelID, "panel id %d is used by another panel", panel.Id)
+			}
+			ids[panel.Id] = true
+		}
+
+		for _, name := range linkVariables(panel.Links) {
+			if !declared[name] {
+				problem(0, ReasonUndeclaredVariable, "variable $%s of a link is not declared in templatings", name)
+			}
+		}
+		if panel.Repeat != "" && !declared[panel.Repeat] {
+			problem(0, ReasonUndeclaredVariable, "variable $%s the panel is repeated by is not declared in templatings", panel.Repeat)
+		}
+
+		refIDs := make(map[int64]bool, len(panel.Targets))
+		refNames := make(map[string]bool, len(panel.Targets))
+		for _, target := range panel.Targets {
+			if target.RefID != 0 {
+				if refIDs[target.RefID] {
+					problem(target.RefID, ReasonDuplicateRefID, "refId %d is used by another target of the panel", target.RefID)
+				}
+				refIDs[target.RefID] = true
+			}
+			if target.RefName != "" {
+				if refNames[target.RefName] {
+					problem(target.RefID, ReasonDuplicateRefID, "refName %q is used by another target of the panel", target.RefName)
+				}
+				refNames[target.RefName] = true
+			}
+			for _, name := range usedVariables(target.Interval) {
+				if !declared[name] {
+					problem(target.RefID, ReasonUndeclaredVariable, "variable $%s is not declared in templatings", name)
+				}
+			}
+
+			if strings.TrimSpace(target.Expression) == "" {
+				problem(target.RefID, ReasonEmptyExpression, "target has no expression")
+				continue
+			}
+			for _, name := range usedVariables(target.Expression) {
+				if !declared[name] {
+					problem(target.RefID, ReasonUndeclaredVariable, "variable $%s is not declared in templatings", name)
+				}
+			}
+			if err := ParseExpression(target.Expression); err != nil {
+				problem(target.RefID, ReasonInvalidExpression, "invalid expression: %v", err)
+			}
+		}
+	}
+
+	return append(problems, validateLayout(in.Panels)...)
+}
+
+// validateLayout checks that the panels, and the copies of repeated ones, fit in the grid, and that the panels shown
+// at the same time don't overlap. Panels of collapsed rows are hidden,
+// so they are only checked against each other.
+func validateLayout(pls []*panels.Panel) []PanelProblem {
+	var problems []PanelProblem
+	problem := func(panel *panels.Panel, reason, format string, args ...interface{}) {
+		problems = append(problems, PanelProblem{
+			PanelID:    panel.Id,
+			PanelTitle: panel.Title,
+			Reason:     reason,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	var placed []*panels.Panel
+	for _, panel := range visiblePanels(pls) {
+		if row := panel.RowPanel; row != nil && row.Collapsed {
+			problems = append(problems, validateLayout(row.Panels)...)
+		}
+
+		if panel.MaxPerRow < 0 || panel.MaxPerRow > panels.GridColumns {
+			problem(panel, ReasonOutOfGrid, "maxPerRow %d is not between 1 and the %d columns of the grid",
+				panel.MaxPerRow, panels.GridColumns)
+		}
+
+		pos := panel.GridPos
+		if pos == nil {
+			continue
+		}
+		if pos.X < 0 || pos.Y < 0 || pos.W <= 0 || pos.H <= 0 || pos.X+pos.W > panels.GridColumns {
+			problem(panel, ReasonOutOfGrid, "panel at x=%d y=%d w=%d h=%d is out of the %d column grid",
+				pos.X, pos.Y, pos.W, pos.H, panels.GridColumns)
+			continue
+		}
+		for _, other := range placed {
+			if pos.Overlaps(*other.GridPos) {
+				problem(panel, ReasonOverlappingPanels, "panel overlaps panel %q", other.Title)
+			}
+		}
+		placed = append(placed, panel)
+	}
+
+	return problems
+}
+
+// visiblePanels lists the panels, and the panels of the rows that are not collapsed
+func visiblePanels(pls []*panels.Panel) []*panels.Panel {
+	var visible []*panels.Panel
+	for _, panel := range pls {
+		if panel == nil {
+			continue
+		}
+		visible = append(visible, panel)
+		if row := panel.RowPanel; row != nil && !row.Collapsed {
+			visible = append(visible, visiblePanels(row.Panels)...)
+		}
+	}
+	return visible
+}
+
+// usedVariables returns the names of the variables referred by s, in order of appearance.
+// Built-in variables, which start with a double underscore, are left out.
+func usedVariables(s string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range variablePattern.FindAllStringSubmatch(s, -1) {
+		name := match[1] + match[2] + match[3]
+		if strings.HasPrefix(name, "__") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func hasCalc(calcs []panels.Calc, calc panels.Calc) bool {
+	for _, c := range calcs {
+		if c == calc {
+			return true
+		}
+	}
+	return false
+}
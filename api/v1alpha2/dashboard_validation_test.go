@@ -0,0 +1,224 @@
+package v1alpha2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+)
+
+func TestValidateDashboardSpec(t *testing.T) {
+	spec := DashboardSpec{
+		Templatings: []templatings.TemplateVar{
+			{CommonVariable: templatings.CommonVariable{Name: "namespace"}},
+			{CommonVariable: templatings.CommonVariable{Name: "pod"}},
+		},
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "row", Title: "Pods"}},
+			{CommonPanel: panels.CommonPanel{Id: 2, Type: "graph", Title: "CPU", Targets: []panels.Target{
+				{RefID: 1, Expression: `sum(rate(container_cpu_usage_seconds_total{namespace="$namespace",pod=~"${pod:regex}"}[$__rate_interval]))`},
+				{RefID: 1, Expression: `sum(kube_pod_container_resource_limits{namespace="[[namespace]]",node="$node"})`},
+			}}},
+			{CommonPanel: panels.CommonPanel{Id: 2, Type: "grpah", Title: "Memory", Targets: []panels.Target{
+				{RefID: 1},
+			}}},
+			nil,
+		},
+	}
+
+	problems := spec.Validate()
+
+	require.Equal(t, []PanelProblem{{
+		PanelID:    2,
+		PanelTitle: "CPU",
+		RefID:      1,
+		Reason:     ReasonDuplicateRefID,
+		Message:    "refId 1 is used by another target of the panel",
+	}, {
+		PanelID:    2,
+		PanelTitle: "CPU",
+		RefID:      1,
+		Reason:     ReasonUndeclaredVariable,
+		Message:    "variable $node is not declared in templatings",
+	}, {
+		PanelID:    2,
+		PanelTitle: "Memory",
+		Reason:     ReasonUnknownType,
+		Message:    `unknown panel type "grpah"`,
+	}, {
+		PanelID:    2,
+		PanelTitle: "Memory",
+		Reason:     ReasonDuplicatePanelID,
+		Message:    "panel id 2 is used by another panel",
+	}, {
+		PanelID:    2,
+		PanelTitle: "Memory",
+		RefID:      1,
+		Reason:     ReasonEmptyExpression,
+		Message:    "target has no expression",
+	}}, problems)
+
+	require.Equal(t, `panel "CPU" refId 1: variable $node is not declared in templatings`, problems[1].String())
+	require.True(t, problems[1].IsQueryProblem())
+	require.False(t, problems[2].IsQueryProblem())
+}
+
+func TestValidateLayout(t *testing.T) {
+	pos := func(x, y, w, h int32) *panels.GridPos {
+		return &panels.GridPos{X: x, Y: y, W: w, H: h}
+	}
+	spec := DashboardSpec{
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "graph", Title: "CPU", GridPos: pos(0, 0, 12, 8)}},
+			{CommonPanel: panels.CommonPanel{Id: 2, Type: "graph", Title: "Memory", GridPos: pos(12, 0, 12, 8)}},
+			{CommonPanel: panels.CommonPanel{Id: 3, Type: "graph", Title: "Network", GridPos: pos(18, 7, 12, 8)}},
+			{CommonPanel: panels.CommonPanel{Id: 4, Type: "text", Title: "Notes"}},
+			{
+				CommonPanel: panels.CommonPanel{Id: 5, Type: "row", Title: "Details", GridPos: pos(0, 8, 24, 1)},
+				RowPanel: &panels.RowPanel{Panels: []*panels.Panel{
+					{CommonPanel: panels.CommonPanel{Id: 6, Type: "graph", Title: "Disk", GridPos: pos(4, 7, 4, 2)}},
+				}},
+			},
+			{
+				CommonPanel: panels.CommonPanel{Id: 7, Type: "row", Title: "Hidden", GridPos: pos(0, 9, 24, 1)},
+				RowPanel: &panels.RowPanel{Collapsed: true, Panels: []*panels.Panel{
+					{CommonPanel: panels.CommonPanel{Id: 8, Type: "graph", Title: "Pods", GridPos: pos(0, 10, 24, 8)}},
+					{CommonPanel: panels.CommonPanel{Id: 9, Type: "graph", Title: "Nodes", GridPos: pos(0, 10, 24, 8)}},
+				}},
+			},
+			{CommonPanel: panels.CommonPanel{Id: 10, Type: "graph", Title: "Below", GridPos: pos(0, 10, 24, 8)}},
+			{CommonPanel: panels.CommonPanel{Id: 11, Type: "graph", Title: "Pods", GridPos: pos(0, 18, 24, 8), MaxPerRow: 30}},
+		},
+	}
+
+	require.Equal(t, []PanelProblem{{
+		PanelID:    3,
+		PanelTitle: "Network",
+		Reason:     ReasonOutOfGrid,
+		Message:    "panel at x=18 y=7 w=12 h=8 is out of the 24 column grid",
+	}, {
+		PanelID:    6,
+		PanelTitle: "Disk",
+		Reason:     ReasonOverlappingPanels,
+		Message:    `panel overlaps panel "CPU"`,
+	}, {
+		PanelID:    6,
+		PanelTitle: "Disk",
+		Reason:     ReasonOverlappingPanels,
+		Message:    `panel overlaps panel "Details"`,
+	}, {
+		PanelID:    9,
+		PanelTitle: "Nodes",
+		Reason:     ReasonOverlappingPanels,
+		Message:    `panel overlaps panel "Pods"`,
+	}, {
+		PanelID:    11,
+		PanelTitle: "Pods",
+		Reason:     ReasonOutOfGrid,
+		Message:    "maxPerRow 30 is not between 1 and the 24 columns of the grid",
+	}}, spec.Validate())
+}
+
+func TestValidateLegend(t *testing.T) {
+	spec := DashboardSpec{
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "graph", Title: "CPU", Legend: &panels.Legend{
+				Calcs: []panels.Calc{panels.CalcMax}, SortBy: panels.CalcMax,
+			}}},
+			{CommonPanel: panels.CommonPanel{Id: 2, Type: "graph", Title: "Memory", Legend: &panels.Legend{
+				Calcs: []panels.Calc{panels.CalcMax}, SortBy: panels.CalcMean,
+			}}},
+		},
+	}
+
+	require.Equal(t, []PanelProblem{{
+		PanelID:    2,
+		PanelTitle: "Memory",
+		Reason:     ReasonInvalidLegend,
+		Message:    `legend is sorted by "mean", which is not one of its calcs`,
+	}}, spec.Validate())
+}
+
+func TestValidateTargets(t *testing.T) {
+	spec := DashboardSpec{
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "graph", Title: "CPU", Targets: []panels.Target{
+				{RefID: 1, RefName: "A", Expression: "up", Interval: "$step"},
+				{RefID: 2, RefName: "A", Expression: "up"},
+				{RefID: 3, RefName: "C", Expression: `label_replace(up, "host", "$1", "instance", "(.*):.*")`},
+			}}},
+		},
+	}
+
+	require.Equal(t, []PanelProblem{
+		{
+			PanelID:    1,
+			PanelTitle: "CPU",
+			RefID:      1,
+			Reason:     ReasonUndeclaredVariable,
+			Message:    "variable $step is not declared in templatings",
+		},
+		{
+			PanelID:    1,
+			PanelTitle: "CPU",
+			RefID:      2,
+			Reason:     ReasonDuplicateRefID,
+			Message:    `refName "A" is used by another target of the panel`,
+		},
+	}, spec.Validate())
+}
+
+func TestValidateLinkVariables(t *testing.T) {
+	spec := DashboardSpec{
+		Templatings: []templatings.TemplateVar{
+			{CommonVariable: templatings.CommonVariable{Name: "namespace"}},
+		},
+		Links: []links.Link{
+			{URL: "https://example.com/logs?namespace=$namespace&${__url_time_range}"},
+			{Type: links.TypeDashboard, Dashboard: "$cluster-pods"},
+		},
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "graph", Title: "CPU", Links: []links.Link{
+				{URL: "/d/pod?var-namespace=$namespace&var-pod=${__field.labels.pod}", Params: "var-node=$node"},
+			}}},
+		},
+	}
+
+	problems := spec.Validate()
+
+	require.Equal(t, []PanelProblem{{
+		Reason:  ReasonUndeclaredVariable,
+		Message: "variable $cluster of a link is not declared in templatings",
+	}, {
+		PanelID:    1,
+		PanelTitle: "CPU",
+		Reason:     ReasonUndeclaredVariable,
+		Message:    "variable $node of a link is not declared in templatings",
+	}}, problems)
+
+	require.Equal(t, "dashboard: variable $cluster of a link is not declared in templatings", problems[0].String())
+}
+
+func TestValidateRepeatVariable(t *testing.T) {
+	spec := DashboardSpec{
+		Templatings: []templatings.TemplateVar{
+			{CommonVariable: templatings.CommonVariable{Name: "gpu"}},
+		},
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "row", Title: "Node", Repeat: "node"}, RowPanel: &panels.RowPanel{
+				Panels: []*panels.Panel{
+					{CommonPanel: panels.CommonPanel{Id: 2, Type: "text", Title: "GPU", Repeat: "gpu"}},
+				},
+			}},
+		},
+	}
+
+	require.Equal(t, []PanelProblem{{
+		PanelID:    1,
+		PanelTitle: "Node",
+		Reason:     ReasonUndeclaredVariable,
+		Message:    "variable $node the panel is repeated by is not declared in templatings",
+	}}, spec.Validate())
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var dashboardlog = logf.Log.WithName("dashboard-resource")
+
+func (r *Dashboard) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+func (r *ClusterDashboard) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-monitoring-kubesphere-io-v1alpha2-dashboard,mutating=true,failurePolicy=fail,sideEffects=None,groups=monitoring.kubesphere.io,resources=dashboards,verbs=create;update,versions=v1alpha2,name=mdashboard.kb.io,admissionReviewVersions={v1,v1beta1}
+// +kubebuilder:webhook:path=/mutate-monitoring-kubesphere-io-v1alpha2-clusterdashboard,mutating=true,failurePolicy=fail,sideEffects=None,groups=monitoring.kubesphere.io,resources=clusterdashboards,verbs=create;update,versions=v1alpha2,name=mclusterdashboard.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Defaulter = &Dashboard{}
+var _ webhook.Defaulter = &ClusterDashboard{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *Dashboard) Default() {
+	dashboardlog.Info("default", "namespace", r.Namespace, "name", r.Name)
+	r.Spec.Default()
+}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ClusterDashboard) Default() {
+	dashboardlog.Info("default", "name", r.Name)
+	r.Spec.Default()
+}
+
+// +kubebuilder:webhook:path=/validate-monitoring-kubesphere-io-v1alpha2-dashboard,mutating=false,failurePolicy=fail,sideEffects=None,groups=monitoring.kubesphere.io,resources=dashboards,verbs=create;update,versions=v1alpha2,name=vdashboard.kb.io,admissionReviewVersions={v1,v1beta1}
+// +kubebuilder:webhook:path=/validate-monitoring-kubesphere-io-v1alpha2-clusterdashboard,mutating=false,failurePolicy=fail,sideEffects=None,groups=monitoring.kubesphere.io,resources=clusterdashboards,verbs=create;update,versions=v1alpha2,name=vclusterdashboard.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &Dashboard{}
+var _ webhook.Validator = &ClusterDashboard{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Dashboard) ValidateCreate() error {
+	dashboardlog.Info("validate create", "namespace", r.Namespace, "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Dashboard) ValidateUpdate(old runtime.Object) error {
+	dashboardlog.Info("validate update", "namespace", r.Namespace, "name", r.Name)
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Dashboard) ValidateDelete() error {
+	return nil
+}
+
+func (r *Dashboard) validate() error {
+	errs := r.Spec.validateQueries(field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Dashboard").GroupKind(), r.Name, errs)
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterDashboard) ValidateCreate() error {
+	dashboardlog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterDashboard) ValidateUpdate(old runtime.Object) error {
+	dashboardlog.Info("validate update", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterDashboard) ValidateDelete() error {
+	return nil
+}
+
+func (r *ClusterDashboard) validate() error {
+	errs := r.Spec.validateQueries(field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ClusterDashboard").GroupKind(), r.Name, errs)
+}
+
+// validateQueries parses every target expression and query variable as PromQL,
+// checks the variables according to their kind, the datasources and the links, and that the time range,
+// the time overrides of the panels and the timezone resolve
+func (in *DashboardSpec) validateQueries(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	errs = append(errs, in.Time.Validate(in.Timezone, path.Child("time"))...)
+	if _, err := time.LoadLocation(in.Timezone); err != nil {
+		errs = append(errs, field.Invalid(path.Child("timezone"), in.Timezone, "must be browser, utc or an IANA timezone name"))
+	}
+
+	errs = append(errs, validatePanelQueries(in.Panels, path.Child("panels"))...)
+	errs = append(errs, in.validateDatasources(path)...)
+	errs = append(errs, in.validateLinks(path)...)
+
+	names := make(map[string]bool, len(in.Templatings))
+	for i, variable := range in.Templatings {
+		variablePath := path.Child("templatings").Index(i)
+		errs = append(errs, variable.Validate(variablePath)...)
+		if variable.Name != "" {
+			if names[variable.Name] {
+				errs = append(errs, field.Duplicate(variablePath.Child("name"), variable.Name))
+			}
+			names[variable.Name] = true
+		}
+
+		if variable.QueryVariable == nil || variable.QueryVariable.Query == "" {
+			continue
+		}
+		if err := ParseVariableQuery(variable.QueryVariable.Query); err != nil {
+			errs = append(errs, field.Invalid(variablePath.Child("query"), variable.QueryVariable.Query,
+				fmt.Sprintf("variable %q: %v", variable.Name, err)))
+		}
+	}
+
+	return errs
+}
+
+// validatePanelQueries parses the target expressions and the time overrides of the panels,
+// and of the panels nested in rows
+func validatePanelQueries(pls []*panels.Panel, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, panel := range pls {
+		if panel == nil {
+			continue
+		}
+		panelPath := path.Index(i)
+		errs = append(errs, time.ValidateOverride(panel.TimeFrom, panel.TimeShift, panelPath)...)
+		for j, target := range panel.Targets {
+			targetPath := panelPath.Child("targets").Index(j)
+			if err := ParseExpression(target.Expression); err != nil {
+				errs = append(errs, field.Invalid(targetPath.Child("expr"), target.Expression,
+					fmt.Sprintf("panel %q refId %d: %v", panel.Title, target.RefID, err)))
+			}
+			errs = append(errs, time.ValidateOverride("", target.TimeShift, targetPath)...)
+		}
+		if panel.RowPanel != nil {
+			errs = append(errs, validatePanelQueries(panel.RowPanel.Panels, panelPath.Child("panels"))...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,311 @@
+package v1alpha2
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSubstituteVariables(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`up`, `up`},
+		{`up{namespace="$namespace"}`, `up{namespace="placeholder"}`},
+		{`rate(http_requests_total{pod=~"${pod:regex}"}[$__rate_interval])`, `rate(http_requests_total{pod=~"placeholder"}[5m])`},
+		{`sum(rate(x[[[interval]]]))`, `sum(rate(x[5m]))`},
+		{`avg_over_time(x[1h:$step]) offset $shift`, `avg_over_time(x[1h:5m]) offset 5m`},
+		{`sum by ($label) (x) > $threshold`, `sum by (placeholder) (x) > placeholder`},
+		{`x{a="[$v]"}`, `x{a="[placeholder]"}`},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, substituteVariables(test.expr), test.expr)
+	}
+}
+
+func TestParseVariableQuery(t *testing.T) {
+	valid := []string{
+		`label_values(job)`,
+		`label_values(up{namespace="$namespace"}, pod)`,
+		`query_result(topk(5, sum by (pod) (rate(container_cpu_usage_seconds_total[$__range]))))`,
+		`label_names()`,
+		`metrics(node_.*)`,
+		`up{job="node"}`,
+	}
+	for _, query := range valid {
+		require.NoError(t, ParseVariableQuery(query), query)
+	}
+
+	invalid := []string{
+		`label_values(up{namespace=}, pod)`,
+		`query_result(sum(rate(x[5m]))`,
+		`up{`,
+	}
+	for _, query := range invalid {
+		require.Error(t, ParseVariableQuery(query), query)
+	}
+}
+
+func TestValidateDashboardWebhook(t *testing.T) {
+	dashboard := &Dashboard{
+		Spec: DashboardSpec{
+			Time: time.Time{From: "now-1h", To: "now"},
+			Templatings: []templatings.TemplateVar{
+				{
+					CommonVariable: templatings.CommonVariable{Name: "namespace", Type: templatings.TypeQuery},
+					QueryVariable:  &templatings.QueryVariable{Query: `label_values(kube_pod_info, namespace)`},
+				},
+				{
+					CommonVariable:   templatings.CommonVariable{Name: "interval", Type: templatings.TypeInterval},
+					IntervalVariable: &templatings.IntervalVariable{Intervals: []string{"1m", "5m", "1h"}},
+				},
+			},
+			Panels: []*panels.Panel{{
+				CommonPanel: panels.CommonPanel{Title: "CPU", Type: "graph", Targets: []panels.Target{
+					{RefID: 1, Expression: `sum(rate(container_cpu_usage_seconds_total{namespace="$namespace"}[$interval]))`},
+				}},
+			}},
+		},
+	}
+	require.NoError(t, dashboard.ValidateCreate())
+
+	dashboard.Spec.Time.From = "yesterday"
+	dashboard.Spec.Templatings[0].QueryVariable.Query = `label_values(kube_pod_info{, namespace)`
+	dashboard.Spec.Panels[0].Targets = append(dashboard.Spec.Panels[0].Targets, panels.Target{
+		RefID:      2,
+		Expression: `sum(rate(container_cpu_usage_seconds_total[5m])`,
+	})
+
+	err := dashboard.ValidateUpdate(dashboard)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `spec.time.from: Invalid value: "yesterday"`)
+	require.Contains(t, err.Error(), `spec.panels[0].targets[1].expr`)
+	require.Contains(t, err.Error(), `panel "CPU" refId 2`)
+	require.Contains(t, err.Error(), `spec.templatings[0].query`)
+	require.NotContains(t, err.Error(), `targets[0]`)
+
+	cluster := &ClusterDashboard{Spec: dashboard.Spec}
+	require.Error(t, cluster.ValidateCreate())
+}
+
+func TestValidateTimeRange(t *testing.T) {
+	spec := DashboardSpec{Time: time.Time{From: "now-1d/d", To: "now-1d/d"}, Timezone: "Asia/Shanghai"}
+	require.Empty(t, spec.validateQueries(field.NewPath("spec")))
+
+	spec = DashboardSpec{Time: time.Time{From: "now", To: "now-6h"}, Timezone: "Mars/Olympus"}
+	errs := spec.validateQueries(field.NewPath("spec"))
+	require.Len(t, errs, 2)
+	require.Equal(t, "spec.time.from", errs[0].Field)
+	require.Equal(t, "spec.timezone", errs[1].Field)
+}
+
+func TestDefaultDashboard(t *testing.T) {
+	dashboard := &Dashboard{
+		Spec: DashboardSpec{
+			Panels: []*panels.Panel{
+				{CommonPanel: panels.CommonPanel{Type: "row", Title: "Overview"}},
+				{CommonPanel: panels.CommonPanel{Id: 4, Type: "graph", Title: "CPU", Targets: []panels.Target{
+					{Expression: "a"},
+					{RefID: 3, Expression: "b", Step: "30s"},
+					{Expression: "c"},
+				}}},
+				{CommonPanel: panels.CommonPanel{Type: "graph", Title: "Memory", Colors: []string{"#000"}}},
+				nil,
+				{CommonPanel: panels.CommonPanel{Type: "singlestat", Title: "Pods", Targets: []panels.Target{{Expression: "d"}}}},
+			},
+		},
+	}
+
+	dashboard.Default()
+
+	spec := dashboard.Spec
+	require.Equal(t, time.Time{From: "now-1h", To: "now"}, spec.Time)
+	require.Empty(t, spec.Timezone)
+	require.Equal(t, int64(5), spec.Panels[0].Id)
+	require.Equal(t, int64(4), spec.Panels[1].Id)
+	require.Equal(t, int64(6), spec.Panels[2].Id)
+	require.Equal(t, int64(7), spec.Panels[4].Id)
+
+	require.Empty(t, spec.Panels[0].Colors)
+	require.Equal(t, panels.DefaultColors(), spec.Panels[1].Colors)
+	require.Equal(t, []string{"#000"}, spec.Panels[2].Colors)
+	require.Empty(t, spec.Panels[4].Colors)
+
+	// steps are left empty for $__interval to be computed, the ones set are kept
+	require.Equal(t, []panels.Target{
+		{RefID: 4, Expression: "a"},
+		{RefID: 3, Expression: "b", Step: "30s"},
+		{RefID: 5, Expression: "c"},
+	}, spec.Panels[1].Targets)
+	require.Equal(t, []panels.Target{{RefID: 1, Expression: "d"}}, spec.Panels[4].Targets)
+
+	// defaulting twice changes nothing
+	defaulted := dashboard.DeepCopy()
+	dashboard.Default()
+	require.Equal(t, defaulted, dashboard)
+}
+
+func TestDefaultMigratesLegendFlags(t *testing.T) {
+	req := require.New(t)
+
+	// the defaulting webhook patches the dashboard it read, which holds the migrated legend
+	var dashboard Dashboard
+	req.NoError(json.Unmarshal([]byte(`{"spec": {"panels": [
+		{"id": 1, "type": "graph", "title": "CPU", "legend": ["as_table", "to_the_right", "max"]}
+	]}}`), &dashboard))
+	dashboard.Default()
+
+	patched, err := json.Marshal(dashboard.Spec.Panels[0])
+	req.NoError(err)
+	req.Contains(string(patched), `"legend":{"displayMode":"table","placement":"right","calcs":["max"]}`)
+}
+
+func TestResolveDatasources(t *testing.T) {
+	req := require.New(t)
+
+	var spec DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"inputs": [
+			{"name": "DS_PROMETHEUS", "pluginId": "prometheus", "datasource": {"uid": "prom"}},
+			{"name": "DS_THANOS", "pluginId": "prometheus"}
+		],
+		"templatings": [{"name": "namespace", "type": "query", "query": "label_values(namespace)", "datasource": "${DS_PROMETHEUS}"}],
+		"panels": [
+			{"type": "row", "panels": [
+				{"type": "graph", "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}, "targets": [{"expr": "up"}]}
+			]},
+			{"type": "graph", "datasource": "-- Mixed --", "targets": [
+				{"expr": "up", "datasource": "$DS_THANOS"},
+				{"expr": "up", "datasource": "$ds"}
+			]}
+		]
+	}`), &spec))
+
+	spec.Default()
+
+	prom := &datasources.DatasourceRef{Type: "prometheus", UID: "prom"}
+	req.Equal(prom, spec.Templatings[0].Datasource)
+	req.Equal(prom, spec.Panels[0].RowPanel.Panels[0].Datasource)
+	req.True(spec.Panels[1].Datasource.IsMixed())
+	req.Equal(&datasources.DatasourceRef{Type: "prometheus"}, spec.Panels[1].Targets[0].Datasource)
+	req.Equal(&datasources.DatasourceRef{UID: "$ds"}, spec.Panels[1].Targets[1].Datasource)
+	req.Len(spec.Inputs, 2)
+}
+
+func TestValidateDatasources(t *testing.T) {
+	req := require.New(t)
+
+	var spec DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"inputs": [{"name": "DS_PROMETHEUS", "pluginId": "prometheus"}],
+		"templatings": [{"name": "ds", "type": "datasource", "pluginId": "prometheus"}],
+		"panels": [
+			{"type": "graph", "datasource": "${DS_PROMETHEUS}"},
+			{"type": "graph", "datasource": "-- Mixed --", "targets": [
+				{"expr": "up", "datasource": "$ds"},
+				{"expr": "up", "datasource": {"type": "prometheus", "uid": "thanos"}}
+			]}
+		]
+	}`), &spec))
+	req.Empty(spec.validateDatasources(field.NewPath("spec")))
+
+	req.NoError(json.Unmarshal([]byte(`{
+		"inputs": [
+			{"name": "DS_PROMETHEUS", "datasource": "$ds"},
+			{"name": "DS_PROMETHEUS"},
+			{"name": "DS-THANOS"}
+		],
+		"templatings": [{"name": "namespace", "type": "query", "query": "label_values(namespace)", "datasource": "-- Mixed --"}],
+		"panels": [
+			{"type": "graph", "datasource": "${DS_LOKI}", "targets": [{"expr": "up", "datasource": "-- Mixed --"}]},
+			{"type": "graph", "datasource": "-- Mixed --", "targets": [{"expr": "up"}]}
+		]
+	}`), &spec))
+	errs := spec.validateDatasources(field.NewPath("spec"))
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	req.Equal([]string{
+		"spec.inputs[0].datasource",
+		"spec.inputs[1].name",
+		"spec.inputs[2].name",
+		"spec.panels[0].datasource",
+		"spec.panels[0].targets[0].datasource",
+		"spec.panels[1].targets[0].datasource",
+		"spec.templatings[0].datasource",
+	}, fields)
+}
+
+func TestValidateLinks(t *testing.T) {
+	req := require.New(t)
+
+	var spec DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"links": [
+			{"title": "Logs", "url": "https://example.com/logs?namespace=${namespace}"},
+			{"type": "dashboard", "dashboard": "pods", "includeVars": true, "keepTime": true},
+			{"type": "dashboards", "tags": ["kubernetes"], "asDropdown": true}
+		],
+		"panels": [
+			{"type": "graph", "links": [{"title": "Pod", "url": "/d/pod?var-pod=${__field.labels.pod}"}]}
+		]
+	}`), &spec))
+	req.Empty(spec.validateLinks(field.NewPath("spec")))
+
+	spec = DashboardSpec{}
+	req.NoError(json.Unmarshal([]byte(`{
+		"links": [
+			{"title": "Logs"},
+			{"type": "dashboard"},
+			{"type": "dashboards"}
+		],
+		"panels": [
+			{"type": "row", "panels": [{"type": "graph", "links": [{"type": "link"}]}]}
+		]
+	}`), &spec))
+	errs := spec.validateLinks(field.NewPath("spec"))
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	req.Equal([]string{
+		"spec.links[0].url",
+		"spec.links[1].dashboard",
+		"spec.links[2].tags",
+		"spec.panels[0].panels[0].links[0].url",
+	}, fields)
+}
+
+// The converted manifests must be accepted by the webhooks. The problems of the grafana
+// dashboards they come from, such as overlapping panels, are only recorded as events.
+func TestValidateConvertedManifests(t *testing.T) {
+	files, err := filepath.Glob("../../manifests/outputs/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			req := require.New(t)
+
+			data, err := ioutil.ReadFile(file)
+			req.NoError(err)
+
+			var dashboard Dashboard
+			req.NoError(yaml.Unmarshal(data, &dashboard))
+			dashboard.Default()
+			req.NoError(dashboard.ValidateCreate())
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// +kubebuilder:object:generate=true
+
+package datasources
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// The mixed datasource lets each target of a panel query its own datasource
+const (
+	MixedUID  = "-- Mixed --"
+	MixedType = "datasource"
+)
+
+// InputType is the type of the grafana inputs holding a datasource
+const InputType = "datasource"
+
+// DatasourceRef refers to a datasource by its uid, or by its name as older dashboards do.
+// A uid or name such as ${DS_PROMETHEUS} refers to an input of the dashboard, or to a datasource variable.
+// Refers to https://grafana.com/docs/grafana/latest/dashboards/json-model/
+// The schema takes both the object and the bare string, which UnmarshalJSON reads.
+// +kubebuilder:validation:Type=""
+// +kubebuilder:pruning:PreserveUnknownFields
+type DatasourceRef struct {
+	// Type of the datasource, such as prometheus
+	Type string `json:"type,omitempty"`
+	// Unique ID of the datasource
+	UID string `json:"uid,omitempty"`
+	// Name of the datasource
+	Name string `json:"name,omitempty"`
+}
+
+// Input is a datasource chosen when the dashboard is applied, such as the DS_PROMETHEUS
+// of the dashboards grafana exports for sharing.
+type Input struct {
+	// Name the references to the input use, such as DS_PROMETHEUS in ${DS_PROMETHEUS}
+	Name string `json:"name"`
+	// Name shown when choosing the datasource
+	Label string `json:"label,omitempty"`
+	// Description
+	Description string `json:"description,omitempty"`
+	// Type of the datasources which fit, such as prometheus
+	PluginID string `json:"pluginId,omitempty"`
+	// Datasource the references to the input are replaced with, the default datasource of PluginID when not set
+	Datasource *DatasourceRef `json:"datasource,omitempty"`
+}
+
+// Mixed returns a reference to the mixed datasource
+func Mixed() *DatasourceRef {
+	return &DatasourceRef{Type: MixedType, UID: MixedUID}
+}
+
+// FromName reads a datasource referred to by a bare string, as the datasources of older dashboards:
+// a variable is taken as a uid, -- Mixed -- as the mixed datasource and anything else as a name.
+// It returns nil for the empty string, which is the default datasource.
+func FromName(name string) *DatasourceRef {
+	switch {
+	case name == "":
+		return nil
+	case name == MixedUID:
+		return Mixed()
+	case variablePattern.MatchString(name):
+		return &DatasourceRef{UID: name}
+	}
+	return &DatasourceRef{Name: name}
+}
+
+// UnmarshalJSON reads references written as objects, or as bare strings like FromName
+func (in *DatasourceRef) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*in = DatasourceRef{}
+		if ref := FromName(name); ref != nil {
+			*in = *ref
+		}
+		return nil
+	}
+
+	type plain DatasourceRef
+	return json.Unmarshal(b, (*plain)(in))
+}
+
+// IsMixed tells whether the reference is to the mixed datasource
+func (in *DatasourceRef) IsMixed() bool {
+	return in != nil && (in.UID == MixedUID || in.Name == MixedUID)
+}
+
+// Variable returns the name of the input or variable the reference is made of, such as DS_PROMETHEUS
+// for ${DS_PROMETHEUS}, or the empty string when it refers to a datasource directly
+func (in *DatasourceRef) Variable() string {
+	if in == nil {
+		return ""
+	}
+	for _, s := range []string{in.UID, in.Name} {
+		if m := variablePattern.FindStringSubmatch(s); m != nil {
+			if m[1] != "" {
+				return m[1]
+			}
+			return m[2]
+		}
+	}
+	return ""
+}
+
+// String is the name of the datasource, or its uid when it has no name
+func (in *DatasourceRef) String() string {
+	if in == nil {
+		return ""
+	}
+	if in.Name != "" {
+		return in.Name
+	}
+	return in.UID
+}
+
+// variablePattern matches a reference made of a single variable: $ds or ${ds}
+var variablePattern = regexp.MustCompile(`^(?:\$(\w+)|\$\{(\w+)\})$`)
@@ -0,0 +1,58 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package datasources
+
+import ()
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatasourceRef) DeepCopyInto(out *DatasourceRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasourceRef.
+func (in *DatasourceRef) DeepCopy() *DatasourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(DatasourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Input) DeepCopyInto(out *Input) {
+	*out = *in
+	if in.Datasource != nil {
+		in, out := &in.Datasource, &out.Datasource
+		*out = new(DatasourceRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Input.
+func (in *Input) DeepCopy() *Input {
+	if in == nil {
+		return nil
+	}
+	out := new(Input)
+	in.DeepCopyInto(out)
+	return out
+}
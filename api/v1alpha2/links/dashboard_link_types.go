@@ -0,0 +1,57 @@
+// +kubebuilder:object:generate=true
+
+package links
+
+// Types of links
+const (
+	// A link to a URL
+	TypeLink = "link"
+	// A link to a dashboard, by name
+	TypeDashboard = "dashboard"
+	// Links to the dashboards carrying all the tags of the link
+	TypeDashboards = "dashboards"
+)
+
+// Kinds of the dashboards a link points at
+const (
+	// Dashboards of the namespace of the dashboard holding the link
+	KindDashboard = "Dashboard"
+	// Cluster dashboards
+	KindClusterDashboard = "ClusterDashboard"
+)
+
+// Link is a link of a dashboard, shown at its top, or of a panel, shown in its menu
+// or on the data points of its series.
+// Refers to https://grafana.com/docs/grafana/latest/linking/
+type Link struct {
+	// Text of the link, the name of the dashboard linked to when empty
+	Title string `json:"title,omitempty"`
+	// What the link points at: a URL, which is the default, a dashboard by name, or the dashboards with the tags
+	// +kubebuilder:validation:Enum=link;dashboard;dashboards
+	Type string `json:"type,omitempty"`
+	// URL of a link, where the variables and the built-in ${__url_time_range} and ${__all_variables}
+	// are expanded. The links of panels may refer to the series clicked, as in ${__field.labels.pod}.
+	URL string `json:"url,omitempty"`
+	// Name of the dashboard linked to
+	Dashboard string `json:"dashboard,omitempty"`
+	// Kind of the dashboards linked to, the kind of the dashboard holding the link when empty.
+	// Cluster dashboards can only link to cluster dashboards.
+	// +kubebuilder:validation:Enum=Dashboard;ClusterDashboard
+	Kind string `json:"kind,omitempty"`
+	// Tags of the dashboards linked to
+	Tags []string `json:"tags,omitempty"`
+	// Show the dashboards with the tags in a dropdown
+	AsDropdown bool `json:"asDropdown,omitempty"`
+	// Icon of the link, such as external link or dashboard
+	Icon string `json:"icon,omitempty"`
+	// Text shown on hover
+	Tooltip string `json:"tooltip,omitempty"`
+	// Open the link in a new tab
+	TargetBlank bool `json:"targetBlank,omitempty"`
+	// Pass the current values of the variables, as var-name=value
+	IncludeVars bool `json:"includeVars,omitempty"`
+	// Pass the current time range, as from and to
+	KeepTime bool `json:"keepTime,omitempty"`
+	// Query parameters added to the link, such as var-namespace=${__field.labels.namespace}
+	Params string `json:"params,omitempty"`
+}
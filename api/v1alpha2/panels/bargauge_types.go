@@ -12,8 +12,8 @@ type BarGaugeOptions struct {
 	Mode        string `json:"mode,omitempty"`
 }
 
+// The fields are displayed according to CommonPanel.FieldConfig.
 // refers to https://pkg.go.dev/github.com/grafana-tools/sdk#BarGaugePanel
 type BarGaugePanel struct {
 	Options *BarGaugeOptions `json:"options,omitempty"`
-	// FieldConfig FieldConfig `json:"fieldConfig,omitempty"`
 }
@@ -2,6 +2,11 @@
 
 package panels
 
+import (
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+)
+
 // Query editor options
 type CommonPanel struct {
 	// Name of the  panel
@@ -12,28 +17,98 @@ type CommonPanel struct {
 	Id int64 `json:"id,omitempty"`
 	// Description
 	Description *string `json:"description,omitempty"`
-	// Datasource
-	Datasource *string `json:"datasource,omitempty"`
+	// Datasource the targets are sent to, the mixed datasource letting each target set its own
+	Datasource *datasources.DatasourceRef `json:"datasource,omitempty"`
 	// Height
-	Height   *string `json:"height,omitempty"`
-	Decimals *int64  `json:"decimals,omitempty"`
+	// Deprecated: use GridPos
+	Height *string `json:"height,omitempty"`
+	// Position and size of the panel in the dashboard grid
+	GridPos  *GridPos `json:"gridPos,omitempty"`
+	Decimals *int64   `json:"decimals,omitempty"`
 	// A collection of queries
 	Targets []Target `json:"targets,omitempty"`
 	// Set series color
 	Colors []string `json:"colors,omitempty"`
-	// legend
-	Legend []string `json:"legend,omitempty"`
+	// How the series are listed
+	Legend *Legend `json:"legend,omitempty"`
 	// Display unit
 	Format string `json:"format,omitempty"`
+	// How stat and gauge panels reduce a series to the value shown
+	ReduceOptions *ReduceOptions `json:"reduceOptions,omitempty"`
+	// How the fields are displayed: units, thresholds, value mappings and overrides.
+	// Used by the stat, gauge, bargauge, timeseries and singlestat panels.
+	FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+	// Links to other dashboards or URLs, from the panel menu or the data points of the series
+	Links []links.Link `json:"links,omitempty"`
+	// Name of the templating variable to repeat the panel, or the row, for: a copy is shown for each selected value
+	Repeat string `json:"repeat,omitempty"`
+	// How the copies of a repeated panel are laid out: side by side, h, which is the default, or stacked, v.
+	// The copies of a row are always stacked.
+	// +kubebuilder:validation:Enum=h;v
+	RepeatDirection string `json:"repeatDirection,omitempty"`
+	// Most copies of a panel repeated horizontally placed side by side, 4 when not set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=24
+	MaxPerRow int32 `json:"maxPerRow,omitempty"`
+	// Time range of the panel instead of the one of the dashboard, up to now: a length of time such as 24h,
+	// or a time relative to now such as now/d. Ignored when the time range of the dashboard is absolute.
+	TimeFrom string `json:"timeFrom,omitempty"`
+	// Moves the time range of the panel back, such as 1w for the same time last week, or 1d/d for yesterday
+	TimeShift string `json:"timeShift,omitempty"`
+	// Don't show the time range of the panel when it isn't the one of the dashboard
+	HideTimeOverride bool `json:"hideTimeOverride,omitempty"`
+}
+
+// Directions of the copies of a repeated panel
+const (
+	RepeatDirectionHorizontal = "h"
+	RepeatDirectionVertical   = "v"
+)
+
+// DefaultMaxPerRow is how many copies of a panel repeated horizontally are placed side by side
+// when its MaxPerRow isn't set
+const DefaultMaxPerRow = 4
+
+// GridColumns is the width of the dashboard grid
+const GridColumns = 24
+
+// Position and size of a panel, in grid units.
+// The grid is GridColumns wide and grows downwards.
+type GridPos struct {
+	// Column of the left edge of the panel
+	X int32 `json:"x,omitempty"`
+	// Row of the top edge of the panel
+	Y int32 `json:"y,omitempty"`
+	// Width in columns
+	W int32 `json:"w,omitempty"`
+	// Height in rows
+	H int32 `json:"h,omitempty"`
+}
+
+// Overlaps tells whether the two areas share a cell
+func (in GridPos) Overlaps(other GridPos) bool {
+	return in.X < other.X+other.W && other.X < in.X+in.W &&
+		in.Y < other.Y+other.H && other.Y < in.Y+in.H
 }
 
 // +kubebuilder:object:generate=true
 
+// Formats of the result of a target
+const (
+	TargetFormatTimeSeries = "time_series"
+	TargetFormatTable      = "table"
+	TargetFormatHeatmap    = "heatmap"
+)
+
 // Query editor options
 // Referers to https://pkg.go.dev/github.com/grafana-tools/sdk#Target
 type Target struct {
 	// Reference ID
 	RefID int64 `json:"refId,omitempty"`
+	// Reference ID of the target in Grafana, such as A, which expressions like $A and overrides refer to
+	RefName string `json:"refName,omitempty"`
+	// Datasource of the target, the datasource of the panel when empty
+	Datasource *datasources.DatasourceRef `json:"datasource,omitempty"`
 	// only support prometheus,and the corresponding fields are as follows:
 	// Input for fetching metrics.
 	Expression string `json:"expr,omitempty"`
@@ -41,4 +116,50 @@ type Target struct {
 	LegendFormat string `json:"legendFormat,omitempty"`
 	// Set series time interval
 	Step string `json:"step,omitempty"`
+	// Evaluate the expression at the end of the time range only
+	Instant bool `json:"instant,omitempty"`
+	// Evaluate the expression over the time range, which is the default unless Instant is set
+	Range bool `json:"range,omitempty"`
+	// How the result is shown: time_series, table or heatmap
+	// +kubebuilder:validation:Enum=time_series;table;heatmap
+	Format string `json:"format,omitempty"`
+	// Lower bound of the resolution, such as 30s or $interval
+	Interval string `json:"interval,omitempty"`
+	// Resolution as a fraction of the computed one: the step is multiplied by the factor
+	// +kubebuilder:validation:Minimum=1
+	IntervalFactor int `json:"intervalFactor,omitempty"`
+	// Don't show the result of the target, other targets may still refer to it
+	Hide bool `json:"hide,omitempty"`
+	// Query the exemplars of the series too
+	Exemplar bool `json:"exemplar,omitempty"`
+	// Moves the time range of the target back from the one of the panel, such as 1w to compare with the
+	// same time last week. The series are moved forward by as much to be shown over the time range of the panel.
+	TimeShift string `json:"timeShift,omitempty"`
+}
+
+// IsRange tells whether the expression is evaluated over the time range
+func (in *Target) IsRange() bool {
+	return in.Range || !in.Instant
+}
+
+// RefIDFromName numbers a Grafana refId made of capital letters like a spreadsheet column:
+// A is 1, Z is 26 and AA is 27. It returns 0 for other names.
+func RefIDFromName(name string) int64 {
+	var id int64
+	for _, c := range name {
+		if c < 'A' || c > 'Z' || id > (1<<62)/26 {
+			return 0
+		}
+		id = id*26 + int64(c-'A'+1)
+	}
+	return id
+}
+
+// RefNameFromID is the reverse of RefIDFromName, it returns an empty string for ids below 1
+func RefNameFromID(id int64) string {
+	var name []byte
+	for ; id > 0; id = (id - 1) / 26 {
+		name = append([]byte{byte('A' + (id-1)%26)}, name...)
+	}
+	return string(name)
 }
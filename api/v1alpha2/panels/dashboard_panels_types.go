@@ -3,9 +3,11 @@
 package panels
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 )
 
 // Supported panel type
@@ -71,143 +73,194 @@ import (
 // }
 
 type (
+	// Panel is a panel of any registered type, see RegisterPanelType.
+	// Fields unknown to the schema are preserved for the panel types added by extensions.
+	// +kubebuilder:pruning:PreserveUnknownFields
 	Panel struct {
-		CommonPanel `json:",inline"`
-		*GraphPanel `json:",inline"`
-		// RowPanel        *RowPanel        `json:",inline"`
+		CommonPanel      `json:",inline"`
+		*GraphPanel      `json:",inline"`
+		*RowPanel        `json:",inline"`
 		*SinglestatPanel `json:",inline"`
 		*TablePanel      `json:",inline"`
 		*TextPanel       `json:",inline"`
 		*BarGaugePanel   `json:",inline"`
-		// *CustomPanel     `json:",inline"`
+		*TimeSeriesPanel `json:",inline"`
+		*StatPanel       `json:",inline"`
+		*GaugePanel      `json:",inline"`
+		*HeatmapPanel    `json:",inline"`
+		// Options of a panel type registered by an extension, or of an unknown type
+		Custom CustomPanel `json:"-"`
 	}
 	probePanel struct {
 		CommonPanel
 	}
 
-	// CustomPanel map[string]runtime.RawExtension
+	// CustomPanel keeps the options of a panel whose type has no field in Panel,
+	// as a JSON object without the common options
+	CustomPanel []byte
 )
 
+// the JSON names of the common options, left out of Panel.Custom
+var commonFields = jsonFieldNames(reflect.TypeOf(CommonPanel{}))
+
 func (p *Panel) UnmarshalJSON(b []byte) (err error) {
 	var probe probePanel
-	if err = json.Unmarshal(b, &probe); err == nil {
-		p.CommonPanel = probe.CommonPanel
-		switch probe.Type {
-		case "graph":
-			var graph GraphPanel
-			if err = json.Unmarshal(b, &graph); err == nil {
-				if !isZero(reflect.ValueOf(graph)) {
-					p.GraphPanel = &graph
-				}
-			}
-		case "table":
-			var table TablePanel
-			if err = json.Unmarshal(b, &table); err == nil {
-				if !isZero(reflect.ValueOf(table)) {
-					p.TablePanel = &table
-				}
-			}
-		case "text":
-			var text TextPanel
-			if err = json.Unmarshal(b, &text); err == nil {
-				if !isZero(reflect.ValueOf(text)) {
-					p.TextPanel = &text
-				}
+	if err = json.Unmarshal(b, &probe); err != nil {
+		return err
+	}
+	*p = Panel{CommonPanel: probe.CommonPanel}
+
+	if factory, ok := lookupPanelType(p.Type); ok {
+		options := factory()
+		if err = json.Unmarshal(b, options); err != nil {
+			return err
+		}
+		if field, ok := p.optionsField(reflect.TypeOf(options)); ok {
+			if !isZero(reflect.ValueOf(options).Elem()) {
+				field.Set(reflect.ValueOf(options))
 			}
-		case "singlestat":
-			var singlestat SinglestatPanel
-			if err = json.Unmarshal(b, &singlestat); err == nil {
-				if !isZero(reflect.ValueOf(singlestat)) {
-					p.SinglestatPanel = &singlestat
-				}
+			return nil
+		}
+	}
+
+	// options of panel types registered by extensions, or of unknown ones, are kept as is
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	for name := range commonFields {
+		delete(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	p.Custom = raw
+	return nil
+}
+
+func (p *Panel) MarshalJSON() ([]byte, error) {
+	common, err := json.Marshal(p.CommonPanel)
+	if err != nil {
+		return nil, err
+	}
+
+	if factory, ok := lookupPanelType(p.Type); ok {
+		if field, ok := p.optionsField(reflect.TypeOf(factory())); ok {
+			if field.IsNil() {
+				return common, nil
 			}
-		case "bargauge":
-			var bargauge BarGaugePanel
-			if err = json.Unmarshal(b, &bargauge); err == nil {
-				if !isZero(reflect.ValueOf(bargauge)) {
-					p.BarGaugePanel = &bargauge
-				}
+			options, err := json.Marshal(field.Interface())
+			if err != nil {
+				return nil, err
 			}
-		case "row":
-			// var row RowPanel
-			// if err = json.Unmarshal(b, &row); err == nil {
-			// 	p.RowPanel = &row
-			// }
-			// default:
-			// 	var custom = make(CustomPanel)
-			// 	if err = json.Unmarshal(b, &custom); err == nil {
-			// 		p.CustomPanel = &custom
-			// 	}
+			return joinObjects(common, options), nil
 		}
 	}
-	return
+
+	return joinObjects(common, p.Custom), nil
 }
 
-func (p *Panel) MarshalJSON() ([]byte, error) {
-	var outCommon = struct {
-		CommonPanel
-	}{p.CommonPanel}
+// Options returns the type specific options of the panel, as a pointer of the type returned by the
+// factory registered for the panel type. It returns nil for an unknown type.
+func (p *Panel) Options() (interface{}, error) {
+	factory, ok := lookupPanelType(p.Type)
+	if !ok {
+		return nil, nil
+	}
 
-	switch p.Type {
-	case "graph":
-		if p.GraphPanel == nil {
-			return json.Marshal(outCommon)
+	options := factory()
+	if field, ok := p.optionsField(reflect.TypeOf(options)); ok {
+		return field.Interface(), nil
+	}
+	if len(p.Custom) > 0 {
+		if err := json.Unmarshal(p.Custom, options); err != nil {
+			return nil, fmt.Errorf("invalid options for panel type %q: %v", p.Type, err)
+		}
+	}
+	return options, nil
+}
+
+// SetOptions replaces the type specific options of the panel.
+// The options must be of the type returned by the factory registered for the panel type.
+func (p *Panel) SetOptions(options interface{}) error {
+	factory, ok := lookupPanelType(p.Type)
+	if !ok {
+		return fmt.Errorf("unknown panel type %q", p.Type)
+	}
+	if want := reflect.TypeOf(factory()); reflect.TypeOf(options) != want {
+		return fmt.Errorf("options of panel type %q must be a %v, not a %T", p.Type, want, options)
+	}
+
+	if field, ok := p.optionsField(reflect.TypeOf(options)); ok {
+		field.Set(reflect.ValueOf(options))
+		return nil
+	}
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	p.Custom = raw
+	return nil
+}
+
+// optionsField returns the field of Panel holding options of the given type, if any
+func (p *Panel) optionsField(t reflect.Type) (reflect.Value, bool) {
+	value := reflect.ValueOf(p).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Anonymous && value.Field(i).Type() == t {
+			return value.Field(i), true
 		}
-		var outGraph = struct {
-			CommonPanel
-			GraphPanel
-		}{p.CommonPanel, *p.GraphPanel}
-		return json.Marshal(outGraph)
-	case "table":
-		if p.TablePanel == nil {
-			return json.Marshal(outCommon)
+	}
+	return reflect.Value{}, false
+}
+
+// joinObjects merges the fields of two JSON objects
+func joinObjects(a, b []byte) []byte {
+	a, b = bytes.TrimSpace(a), bytes.TrimSpace(b)
+	switch {
+	case len(b) < 2 || bytes.Equal(b, []byte("{}")):
+		return a
+	case bytes.Equal(a, []byte("{}")):
+		return b
+	}
+	joined := append([]byte{}, a[:len(a)-1]...)
+	joined = append(joined, ',')
+	return append(joined, b[1:]...)
+}
+
+// jsonFieldNames lists the JSON names of the fields of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
 		}
-		var outTable = struct {
-			CommonPanel
-			TablePanel
-		}{p.CommonPanel, *p.TablePanel}
-		return json.Marshal(outTable)
-	case "text":
-		if p.TextPanel == nil {
-			return json.Marshal(outCommon)
+		if name != "-" {
+			names[name] = true
 		}
-		var outText = struct {
-			CommonPanel
-			TextPanel
-		}{p.CommonPanel, *p.TextPanel}
-		return json.Marshal(outText)
-	case "singlestat":
-		if p.SinglestatPanel == nil {
-			return json.Marshal(outCommon)
+	}
+	return names
+}
+
+// Flatten lists the given panels followed by the panels nested in them, in display order.
+// Nil panels are left out.
+func Flatten(pls []*Panel) []*Panel {
+	var flat []*Panel
+	for _, panel := range pls {
+		if panel == nil {
+			continue
 		}
-		var outSinglestat = struct {
-			CommonPanel
-			SinglestatPanel
-		}{p.CommonPanel, *p.SinglestatPanel}
-		return json.Marshal(outSinglestat)
-
-	case "bargauge":
-		if p.BarGaugePanel == nil {
-			return json.Marshal(outCommon)
+		flat = append(flat, panel)
+		if panel.RowPanel != nil {
+			flat = append(flat, Flatten(panel.RowPanel.Panels)...)
 		}
-		var outBarGauge = struct {
-			CommonPanel
-			BarGaugePanel
-		}{p.CommonPanel, *p.BarGaugePanel}
-		return json.Marshal(outBarGauge)
-	case "row":
-		var outRow = struct {
-			CommonPanel
-		}{p.CommonPanel}
-		return json.Marshal(outRow)
-		// default:
-		// 	var outCustom = struct {
-		// 		CommonPanel
-		// 		CustomPanel
-		// 	}{p.CommonPanel, *p.CustomPanel}
-		// 	return json.Marshal(outCustom)
-	}
-	return nil, errors.New("can't marshal unknown panel type")
+	}
+	return flat
 }
 
 func isZero(value reflect.Value) bool {
@@ -0,0 +1,95 @@
+// +kubebuilder:object:generate=true
+
+package panels
+
+// Threshold modes
+const (
+	ThresholdsModeAbsolute   = "absolute"
+	ThresholdsModePercentage = "percentage"
+)
+
+// Value mapping types
+const (
+	MappingTypeValue = "value"
+	MappingTypeRange = "range"
+	MappingTypeRegex = "regex"
+)
+
+// FieldConfig tells how the fields of the query results are displayed
+// refers to https://grafana.com/docs/grafana/latest/panels/field-options/
+type FieldConfig struct {
+	// Options applied to every field
+	Defaults FieldOptions `json:"defaults,omitempty"`
+	// Options applied to the matching fields only, in order
+	Overrides []FieldOverride `json:"overrides,omitempty"`
+}
+
+// Display options of a field
+type FieldOptions struct {
+	// Display unit
+	Unit string `json:"unit,omitempty"`
+	// Limit the decimal numbers
+	Decimals *int64 `json:"decimals,omitempty"`
+	// Lowest value of the field, computed from the data when empty
+	Min string `json:"min,omitempty"`
+	// Highest value of the field, computed from the data when empty
+	Max string `json:"max,omitempty"`
+	// Name shown instead of the series name
+	DisplayName string `json:"displayName,omitempty"`
+	// Colors of the value depending on where it falls
+	Thresholds *Thresholds `json:"thresholds,omitempty"`
+	// Texts shown instead of the matching values
+	Mappings []ValueMapping `json:"mappings,omitempty"`
+}
+
+// Thresholds split the values in colored steps
+type Thresholds struct {
+	// How the step values are read: absolute or percentage of the min/max range
+	// +kubebuilder:validation:Enum=absolute;percentage
+	Mode string `json:"mode,omitempty"`
+	// Steps in ascending order of value
+	Steps []ThresholdStep `json:"steps,omitempty"`
+}
+
+// A threshold step colors the values from its own value up to the value of the next step
+type ThresholdStep struct {
+	// Lowest value of the step, the base step has no value and starts at minus infinity
+	Value string `json:"value,omitempty"`
+	// Color of the step
+	Color string `json:"color,omitempty"`
+}
+
+// ValueMapping shows a text and color in place of the matching values
+type ValueMapping struct {
+	// How values are matched: value, range or regex
+	// +kubebuilder:validation:Enum=value;range;regex
+	Type string `json:"type"`
+	// The value matched by a value mapping
+	Value string `json:"value,omitempty"`
+	// The lowest value matched by a range mapping
+	From string `json:"from,omitempty"`
+	// The highest value matched by a range mapping
+	To string `json:"to,omitempty"`
+	// The regular expression matched by a regex mapping
+	Pattern string `json:"pattern,omitempty"`
+	// Text shown instead of the value
+	Text string `json:"text,omitempty"`
+	// Color of the text
+	Color string `json:"color,omitempty"`
+}
+
+// FieldOverride changes the display options of some fields
+type FieldOverride struct {
+	// Selects the fields
+	Matcher FieldMatcher `json:"matcher"`
+	// Options replacing the defaults for the selected fields
+	Properties FieldOptions `json:"properties,omitempty"`
+}
+
+// FieldMatcher selects fields
+type FieldMatcher struct {
+	// Kind of match, such as byName, byRegexp or byType
+	ID string `json:"id"`
+	// Argument of the match, such as the field name
+	Options string `json:"options,omitempty"`
+}
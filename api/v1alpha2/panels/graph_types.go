@@ -2,7 +2,26 @@
 
 package panels
 
+// How a graph draws the missing points of a series
+const (
+	// Join the points around the gap
+	NullPointModeConnected = "connected"
+	// Leave a gap
+	NullPointModeNull = "null"
+	// Draw the missing points at zero
+	NullPointModeNullAsZero = "null as zero"
+)
+
+// Tooltip modes of a graph
+const (
+	// List all the series at the hovered time
+	TooltipModeShared = "shared"
+	// Show the hovered series only
+	TooltipModeSingle = "single"
+)
+
 // Graph visualizes range query results into a linear graph
+// refers to https://grafana.com/docs/grafana/latest/visualizations/graph-panel/
 type GraphPanel struct {
 	// Display as a bar chart
 	Bars bool `json:"bars,omitempty"`
@@ -10,9 +29,34 @@ type GraphPanel struct {
 	Lines bool `json:"lines,omitempty"`
 	// Display as a stacked chart
 	Stack bool `json:"stack,omitempty"`
+	// Draw the points of the series
+	Points bool `json:"points,omitempty"`
+	// Opacity of the area under the lines, from 0, no area, to 10
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	Fill int32 `json:"fill,omitempty"`
+	// Width of the lines in pixels
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	LineWidth int32 `json:"lineWidth,omitempty"`
+	// Radius of the points in pixels, such as 0.5 or 2
+	PointRadius string `json:"pointRadius,omitempty"`
+	// How missing points are drawn: connected, null, which leaves a gap, or null as zero
+	// +kubebuilder:validation:Enum=connected;"null";"null as zero"
+	NullPointMode string `json:"nullPointMode,omitempty"`
+	// Stack the series as percentages of their total
+	Percentage bool `json:"percentage,omitempty"`
+	// Draw the lines as steps
+	SteppedLine bool `json:"steppedLine,omitempty"`
+	// What the tooltip shows
+	Tooltip *Tooltip `json:"tooltip,omitempty"`
+	// Lines and areas marking values
+	Thresholds []GraphThreshold `json:"thresholds,omitempty"`
+	// Display options of the series matching an alias, applied in order
+	SeriesOverrides []SeriesOverride `json:"seriesOverrides,omitempty"`
 
 	Xaxis Axis `json:"xaxis,omitempty"`
-	// Y-axis options
+	// Y-axis options: the left axis, then the right one
 	Yaxes []Axis `json:"yaxes,omitempty"`
 }
 
@@ -21,19 +65,83 @@ type Axis struct {
 	Decimals int64 `json:"decimals,omitempty"`
 	// Display unit
 	Format string `json:"format,omitempty"`
+	// Lowest value of the axis, computed from the data when empty
+	Min string `json:"min,omitempty"`
+	// Highest value of the axis, computed from the data when empty
+	Max string `json:"max,omitempty"`
+	// Base of a logarithmic scale, the scale is linear when not set or 1
+	// +kubebuilder:validation:Enum=1;2;10;32;1024
+	LogBase int32 `json:"logBase,omitempty"`
+	// Text shown along the axis
+	Label string `json:"label,omitempty"`
+	// Show the axis, which is the default
+	Show *bool `json:"show,omitempty"`
+}
+
+// Tooltip tells what the tooltip of a graph shows
+type Tooltip struct {
+	// List all the series at the hovered time, shared, which is the default, or the hovered series, single
+	// +kubebuilder:validation:Enum=shared;single
+	Mode string `json:"mode,omitempty"`
+	// Order of the series of a shared tooltip: none, increasing or decreasing
+	// +kubebuilder:validation:Enum=none;increasing;decreasing
+	Sort string `json:"sort,omitempty"`
+	// Value shown for stacked series: their own, individual, or the stacked one, cumulative
+	// +kubebuilder:validation:Enum=individual;cumulative
+	ValueType string `json:"valueType,omitempty"`
 }
 
-// type Legend struct {
-// 	AlignAsTable bool  `json:"alignAsTable,omitempty"`
-// 	Avg          bool  `json:"avg,omitempty"`
-// 	Current      bool  `json:"current,omitempty" `
-// 	HideEmpty    bool  `json:"hideEmpty,omitempty"`
-// 	HideZero     bool  `json:"hideZero,omitempty"`
-// 	Max          bool  `json:"max,omitempty"`
-// 	Min          bool  `json:"min,omitempty"`
-// 	RightSide    bool  `json:"rightSide,omitempty"`
-// 	Show         bool  `json:"show,omitempty"`
-// 	SideWidth    *uint `json:"sideWidth,omitempty"`
-// 	Total        bool  `json:"total,omitempty"`
-// 	Values       bool  `json:"values,omitempty"`
-// }
+// GraphThreshold marks the values above or below a value with a line, an area or both
+type GraphThreshold struct {
+	// Value of the threshold
+	Value string `json:"value"`
+	// Mark the values greater, gt, or lower, lt, than the threshold
+	// +kubebuilder:validation:Enum=gt;lt
+	Op string `json:"op,omitempty"`
+	// Colors of the marks: critical, warning, ok, or custom, which uses FillColor and LineColor
+	// +kubebuilder:validation:Enum=critical;warning;ok;custom
+	ColorMode string `json:"colorMode,omitempty"`
+	// Fill the area of the marked values
+	Fill bool `json:"fill,omitempty"`
+	// Draw a line at the threshold
+	Line bool `json:"line,omitempty"`
+	// Color of the area, in custom color mode
+	FillColor string `json:"fillColor,omitempty"`
+	// Color of the line, in custom color mode
+	LineColor string `json:"lineColor,omitempty"`
+	// Y-axis of the value: left, which is the default, or right
+	// +kubebuilder:validation:Enum=left;right
+	Yaxis string `json:"yaxis,omitempty"`
+}
+
+// SeriesOverride changes how the series matching an alias are drawn. Unset options are the ones of the graph.
+type SeriesOverride struct {
+	// Name of the series, or a regular expression between slashes such as /^cpu/
+	Alias string `json:"alias"`
+	// Y-axis of the series: 1 for the left one, 2 for the right one
+	// +kubebuilder:validation:Enum=1;2
+	Yaxis int32 `json:"yaxis,omitempty"`
+	// Color of the series
+	Color string `json:"color,omitempty"`
+	// Opacity of the area under the line, from 0 to 10
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	Fill *int32 `json:"fill,omitempty"`
+	// Width of the line in pixels
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	LineWidth *int32 `json:"lineWidth,omitempty"`
+	// Draw the series as bars
+	Bars *bool `json:"bars,omitempty"`
+	// Draw the series as a line
+	Lines *bool `json:"lines,omitempty"`
+	// Draw the line dashed
+	Dashes *bool `json:"dashes,omitempty"`
+	// Stack the series with the others
+	Stack *bool `json:"stack,omitempty"`
+	// List the series in the legend
+	Legend *bool `json:"legend,omitempty"`
+	// How missing points are drawn: connected, null or null as zero
+	// +kubebuilder:validation:Enum=connected;"null";"null as zero"
+	NullPointMode string `json:"nullPointMode,omitempty"`
+}
@@ -0,0 +1,72 @@
+// +kubebuilder:object:generate=true
+
+package panels
+
+// How the query results of a heatmap are read
+const (
+	// Each series is a list of values, counted into buckets by the panel
+	HeatmapDataFormatTimeSeries = "timeseries"
+	// Each series is a bucket, such as the buckets of a Prometheus histogram, named after its bound
+	HeatmapDataFormatTSBuckets = "tsbuckets"
+)
+
+// How the cells of a heatmap are colored
+const (
+	// A color of the scheme for each count
+	HeatmapColorModeSpectrum = "spectrum"
+	// The card color, more or less opaque depending on the count
+	HeatmapColorModeOpacity = "opacity"
+)
+
+// Heatmap shows how values are distributed over time, as cells colored by the count of values
+// of each time interval and bucket.
+// refers to https://grafana.com/docs/grafana/latest/visualizations/heatmap/
+type HeatmapPanel struct {
+	// How the query results are read: timeseries, which is the default, or tsbuckets
+	// for series that are already buckets, such as a histogram queried with the heatmap format
+	// +kubebuilder:validation:Enum=timeseries;tsbuckets
+	DataFormat string `json:"dataFormat,omitempty"`
+	// Colors of the cells
+	Color *HeatmapColor `json:"color,omitempty"`
+	// Leave out the buckets without any value
+	HideZeroBuckets bool `json:"hideZeroBuckets,omitempty"`
+	// Highlight the hovered cell
+	HighlightCards bool `json:"highlightCards,omitempty"`
+	// Show the buckets from top to bottom
+	ReverseYBuckets bool `json:"reverseYBuckets,omitempty"`
+	// Options of the y-axis
+	YAxis Axis `json:"yAxis,omitempty"`
+	// Which bound of the tsbuckets the y-axis shows for a bucket: auto, upper, lower or middle
+	// +kubebuilder:validation:Enum=auto;upper;lower;middle
+	YBucketBound string `json:"yBucketBound,omitempty"`
+	// Number of the buckets of the y-axis, computed from the data when not set
+	// +kubebuilder:validation:Minimum=1
+	YBucketNumber int32 `json:"yBucketNumber,omitempty"`
+	// Size of the buckets of the y-axis, which takes precedence over their number
+	YBucketSize string `json:"yBucketSize,omitempty"`
+	// Don't show the tooltip
+	HideTooltip bool `json:"hideTooltip,omitempty"`
+	// Show the histogram of the hovered time interval in the tooltip
+	TooltipHistogram bool `json:"tooltipHistogram,omitempty"`
+}
+
+// HeatmapColor tells how the cells of a heatmap are colored
+type HeatmapColor struct {
+	// A color of the scheme for each count, spectrum, which is the default, or the card color
+	// more or less opaque, opacity
+	// +kubebuilder:validation:Enum=spectrum;opacity
+	Mode string `json:"mode,omitempty"`
+	// Color scheme of the spectrum mode, such as interpolateOranges
+	ColorScheme string `json:"colorScheme,omitempty"`
+	// Color of the cells in opacity mode
+	CardColor string `json:"cardColor,omitempty"`
+	// How the opacity grows with the count in opacity mode: linear or sqrt
+	// +kubebuilder:validation:Enum=linear;sqrt
+	ColorScale string `json:"colorScale,omitempty"`
+	// Exponent of the sqrt scale
+	Exponent string `json:"exponent,omitempty"`
+	// Count of the first color, computed from the data when empty
+	Min string `json:"min,omitempty"`
+	// Count of the last color, computed from the data when empty
+	Max string `json:"max,omitempty"`
+}
@@ -0,0 +1,119 @@
+// +kubebuilder:object:generate=true
+
+package panels
+
+import (
+	"encoding/json"
+)
+
+// Legend display modes
+const (
+	LegendDisplayList   = "list"
+	LegendDisplayTable  = "table"
+	LegendDisplayHidden = "hidden"
+)
+
+// Legend placements
+const (
+	LegendPlacementBottom = "bottom"
+	LegendPlacementRight  = "right"
+)
+
+// Calculations computing a single value from a series
+const (
+	CalcMin         Calc = "min"
+	CalcMax         Calc = "max"
+	CalcMean        Calc = "mean"
+	CalcSum         Calc = "sum"
+	CalcLast        Calc = "last"
+	CalcLastNotNull Calc = "lastNotNull"
+)
+
+// Calc is a calculation computing a single value from a series
+// refers to https://grafana.com/docs/grafana/latest/panels/calculation-types/
+// +kubebuilder:validation:Enum=min;max;mean;sum;count;first;firstNotNull;last;lastNotNull;range;delta;diff;stdDev;variance
+type Calc string
+
+// Legend tells how the series of a panel are listed.
+// A legend written as the list of flags that used to describe it is migrated by UnmarshalJSON
+// when the defaulting webhook reads the dashboard, before the schema is checked.
+type Legend struct {
+	// How the legend is shown: list, table or hidden
+	// +kubebuilder:validation:Enum=list;table;hidden
+	DisplayMode string `json:"displayMode,omitempty"`
+	// Where the legend is shown: bottom or right
+	// +kubebuilder:validation:Enum=bottom;right
+	Placement string `json:"placement,omitempty"`
+	// Values computed for every series and shown next to its name
+	Calcs []Calc `json:"calcs,omitempty"`
+	// Sort the series by one of the calcs
+	SortBy Calc `json:"sortBy,omitempty"`
+	// Sort in descending order
+	SortDesc bool `json:"sortDesc,omitempty"`
+	// Leave out the series with only null values
+	HideEmpty bool `json:"hideEmpty,omitempty"`
+	// Leave out the series with only zero values
+	HideZero bool `json:"hideZero,omitempty"`
+}
+
+// the calcs of a legend used to be flags named after the grafana graph legend options
+var legacyCalcFlags = []struct {
+	flag string
+	calc Calc
+}{
+	{"min", CalcMin},
+	{"max", CalcMax},
+	{"avg", CalcMean},
+	{"current", CalcLastNotNull},
+	{"total", CalcSum},
+}
+
+// LegendFromFlags migrates the list of flags that used to describe a legend,
+// such as "hide", "as_table", "to_the_right", "avg" and "no_zero_series".
+// Unknown flags are left out. It returns nil for an empty list.
+func LegendFromFlags(flags []string) *Legend {
+	if len(flags) == 0 {
+		return nil
+	}
+
+	legend := &Legend{}
+	for _, flag := range flags {
+		switch flag {
+		case "hide":
+			legend.DisplayMode = LegendDisplayHidden
+		case "as_table":
+			if legend.DisplayMode != LegendDisplayHidden {
+				legend.DisplayMode = LegendDisplayTable
+			}
+		case "to_the_right":
+			legend.Placement = LegendPlacementRight
+		case "no_null_series":
+			legend.HideEmpty = true
+		case "no_zero_series":
+			legend.HideZero = true
+		default:
+			for _, c := range legacyCalcFlags {
+				if c.flag == flag {
+					legend.Calcs = append(legend.Calcs, c.calc)
+				}
+			}
+		}
+	}
+	return legend
+}
+
+// UnmarshalJSON reads a legend, or the list of flags that used to describe one
+func (in *Legend) UnmarshalJSON(b []byte) error {
+	var flags []string
+	if err := json.Unmarshal(b, &flags); err == nil {
+		if legend := LegendFromFlags(flags); legend != nil {
+			*in = *legend
+		} else {
+			*in = Legend{}
+		}
+		return nil
+	}
+
+	type plain Legend
+	return json.Unmarshal(b, (*plain)(in))
+}
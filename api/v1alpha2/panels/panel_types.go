@@ -0,0 +1,37 @@
+// +kubebuilder:object:generate=true
+
+// Package panels contains the panels of a dashboard.
+// Numbers are kept as strings since a CRD can not hold floats.
+package panels
+
+// Panel types understood by the dashboard
+const (
+	TypeGraph      = "graph"
+	TypeSinglestat = "singlestat"
+	TypeTable      = "table"
+	TypeText       = "text"
+	TypeBarGauge   = "bargauge"
+	TypeRow        = "row"
+	TypeTimeSeries = "timeseries"
+	TypeStat       = "stat"
+	TypeGauge      = "gauge"
+	TypeHeatmap    = "heatmap"
+)
+
+func init() {
+	RegisterPanelType(TypeGraph, func() interface{} { return &GraphPanel{} })
+	RegisterPanelType(TypeSinglestat, func() interface{} { return &SinglestatPanel{} })
+	RegisterPanelType(TypeTable, func() interface{} { return &TablePanel{} })
+	RegisterPanelType(TypeText, func() interface{} { return &TextPanel{} })
+	RegisterPanelType(TypeBarGauge, func() interface{} { return &BarGaugePanel{} })
+	RegisterPanelType(TypeRow, func() interface{} { return &RowPanel{} })
+	RegisterPanelType(TypeTimeSeries, func() interface{} { return &TimeSeriesPanel{} })
+	RegisterPanelType(TypeStat, func() interface{} { return &StatPanel{} })
+	RegisterPanelType(TypeGauge, func() interface{} { return &GaugePanel{} })
+	RegisterPanelType(TypeHeatmap, func() interface{} { return &HeatmapPanel{} })
+}
+
+// DefaultColors is the series palette used when a graph sets no colors
+func DefaultColors() []string {
+	return []string{"#60acfc", "#23c2db", "#64d5b2", "#d5ec5a", "#ffb64e", "#fb816d", "#d15c7f"}
+}
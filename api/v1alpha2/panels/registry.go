@@ -0,0 +1,49 @@
+package panels
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PanelFactory returns a pointer to new, empty options of a panel type
+// +kubebuilder:object:generate=false
+type PanelFactory func() interface{}
+
+var (
+	panelTypesMu sync.RWMutex
+	panelTypes   = make(map[string]PanelFactory)
+)
+
+// RegisterPanelType makes a panel type known to the Panel JSON codec.
+// The factory returns a pointer to the struct holding the options of the type, which are
+// read from and written to the panel JSON next to the common options.
+// The options of the built-in types live in the matching field of Panel, those of other
+// types are kept as raw JSON in Panel.Custom and decoded on demand by Panel.Options.
+// It panics when the name is empty or already registered, and is meant to be called from init.
+func RegisterPanelType(name string, factory PanelFactory) {
+	panelTypesMu.Lock()
+	defer panelTypesMu.Unlock()
+
+	if name == "" || factory == nil {
+		panic("panels: RegisterPanelType needs a name and a factory")
+	}
+	if _, dup := panelTypes[name]; dup {
+		panic(fmt.Sprintf("panels: panel type %q is registered twice", name))
+	}
+	panelTypes[name] = factory
+}
+
+// lookupPanelType returns the factory registered for the panel type
+func lookupPanelType(name string) (PanelFactory, bool) {
+	panelTypesMu.RLock()
+	defer panelTypesMu.RUnlock()
+
+	factory, ok := panelTypes[name]
+	return factory, ok
+}
+
+// IsKnownType reports whether t is a registered panel type
+func IsKnownType(t string) bool {
+	_, ok := lookupPanelType(t)
+	return ok
+}
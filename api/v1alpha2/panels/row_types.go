@@ -2,6 +2,13 @@
 
 package panels
 
-// Row groups relevant charts
+// Row groups relevant charts.
+// A row is repeated, with its panels, by the Repeat of its CommonPanel.
 type RowPanel struct {
+	// Hide the panels of the row
+	Collapsed bool `json:"collapsed,omitempty"`
+	// Panels grouped in the row
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Panels []*Panel `json:"panels,omitempty"`
 }
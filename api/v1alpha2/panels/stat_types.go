@@ -0,0 +1,41 @@
+// +kubebuilder:object:generate=true
+
+package panels
+
+// ReduceOptions tells how a series is reduced to the value shown
+type ReduceOptions struct {
+	// Reducers to apply, such as lastNotNull, mean or max
+	Calcs []string `json:"calcs,omitempty"`
+	// Regular expression of the fields to show, numeric fields when empty
+	Fields string `json:"fields,omitempty"`
+	// Show every value instead of a reduced one
+	Values bool `json:"values,omitempty"`
+}
+
+// Stat shows one big value per series, the successor of singlestat
+// The series are reduced according to CommonPanel.ReduceOptions
+// and displayed according to CommonPanel.FieldConfig.
+// refers to https://grafana.com/docs/grafana/latest/panels/visualizations/stat-panel/
+type StatPanel struct {
+	// Layout of the values: auto, horizontal or vertical
+	Orientation string `json:"orientation,omitempty"`
+	// What is shown: auto, value, value_and_name, name or none
+	TextMode string `json:"textMode,omitempty"`
+	// What the threshold color applies to: value or background
+	ColorMode string `json:"colorMode,omitempty"`
+	// Draw a sparkline behind the value: none or area
+	GraphMode string `json:"graphMode,omitempty"`
+}
+
+// Gauge shows the values on a dial
+// The series are reduced according to CommonPanel.ReduceOptions
+// and displayed according to CommonPanel.FieldConfig.
+// refers to https://grafana.com/docs/grafana/latest/panels/visualizations/gauge-panel/
+type GaugePanel struct {
+	// Layout of the gauges: auto, horizontal or vertical
+	Orientation string `json:"orientation,omitempty"`
+	// Show the threshold values around the dial
+	ShowThresholdLabels bool `json:"showThresholdLabels,omitempty"`
+	// Show the threshold band around the dial
+	ShowThresholdMarkers bool `json:"showThresholdMarkers,omitempty"`
+}
@@ -2,13 +2,100 @@
 
 package panels
 
+// How the value of a column is shown
+const (
+	ColumnTypeNumber = "number"
+	ColumnTypeString = "string"
+	ColumnTypeDate   = "date"
+)
+
+// Transformations of the query results of a table
+const (
+	// Join the results of the targets into one table, on the columns they share
+	TransformationMerge = "merge"
+	// Reorder, rename and hide columns
+	TransformationOrganize = "organize"
+	// Reduce each series to a row of values computed from it
+	TransformationReduce = "reduce"
+)
+
 // a table panel
 type TablePanel struct {
 	Sort   *Sort `json:"sort,omitempty"`
 	Scroll bool  `json:"scroll,omitempty"`
+	// How the columns are shown, the first style matching a column applies
+	Styles []ColumnStyle `json:"styles,omitempty"`
+	// Columns left out of the table, by name or by a regular expression between slashes
+	HiddenColumns []string `json:"hiddenColumns,omitempty"`
+	// Changes applied to the query results before they are shown, in order
+	Transformations []Transformation `json:"transformations,omitempty"`
 }
 
 type Sort struct {
 	Col  int  `json:"col,omitempty"`
 	Desc bool `json:"desc,omitempty"`
 }
+
+// ColumnStyle tells how the matching columns of a table are shown
+type ColumnStyle struct {
+	// Name of the columns, or a regular expression between slashes such as /^pod/
+	Pattern string `json:"pattern"`
+	// Header of the columns instead of their name
+	Alias string `json:"alias,omitempty"`
+	// How the values are shown: number, string or date
+	// +kubebuilder:validation:Enum=number;string;date
+	Type string `json:"type,omitempty"`
+	// Display unit of a number
+	Unit string `json:"unit,omitempty"`
+	// Limit the decimal numbers
+	Decimals *int64 `json:"decimals,omitempty"`
+	// Format of a date, such as YYYY-MM-DD HH:mm:ss
+	DateFormat string `json:"dateFormat,omitempty"`
+	// Values from which the colors after the first one apply, in ascending order
+	Thresholds []string `json:"thresholds,omitempty"`
+	// Colors of the values, one more than the thresholds
+	Colors []string `json:"colors,omitempty"`
+	// What the colors apply to: the cell, the value or the whole row
+	// +kubebuilder:validation:Enum=cell;value;row
+	ColorMode string `json:"colorMode,omitempty"`
+	// Link of the cells
+	Link *ColumnLink `json:"link,omitempty"`
+}
+
+// ColumnLink turns the cells of a column into links
+type ColumnLink struct {
+	// URL of the link, which may use variables and the cell value as ${__cell}
+	URL string `json:"url"`
+	// Text shown when hovering the link
+	Tooltip string `json:"tooltip,omitempty"`
+	// Open the link in a new tab
+	TargetBlank bool `json:"targetBlank,omitempty"`
+}
+
+// Transformation changes the query results of a table
+// refers to https://grafana.com/docs/grafana/latest/panels/transformations/types-options/
+type Transformation struct {
+	// Kind of the transformation: merge, organize or reduce
+	// +kubebuilder:validation:Enum=merge;organize;reduce
+	ID string `json:"id"`
+	// Options of the transformation, depending on its kind
+	Options *TransformationOptions `json:"options,omitempty"`
+}
+
+// TransformationOptions holds the options of all the kinds of transformations
+type TransformationOptions struct {
+	// Columns left out by an organize transformation
+	ExcludeByName map[string]bool `json:"excludeByName,omitempty"`
+	// Positions of the columns, from 0, set by an organize transformation
+	IndexByName map[string]int32 `json:"indexByName,omitempty"`
+	// Headers of the columns set by an organize transformation
+	RenameByName map[string]string `json:"renameByName,omitempty"`
+	// Values computed for every series by a reduce transformation
+	Reducers []Calc `json:"reducers,omitempty"`
+	// How a reduce transformation lays out the values: a row per series, seriesToRows,
+	// which is the default, or a column per series, reduceFields
+	// +kubebuilder:validation:Enum=seriesToRows;reduceFields
+	Mode string `json:"mode,omitempty"`
+	// Keep the time column in a reduce transformation
+	IncludeTimeField bool `json:"includeTimeField,omitempty"`
+}
@@ -0,0 +1,39 @@
+// +kubebuilder:object:generate=true
+
+package panels
+
+// Draw styles of a time series panel
+const (
+	DrawStyleLine   = "line"
+	DrawStyleBars   = "bars"
+	DrawStylePoints = "points"
+)
+
+// Stacking modes of a time series panel
+const (
+	StackingNone    = "none"
+	StackingNormal  = "normal"
+	StackingPercent = "percent"
+)
+
+// TimeSeries visualizes range query results, the way Grafana 7 and later does
+// The fields are displayed according to CommonPanel.FieldConfig.
+// refers to https://grafana.com/docs/grafana/latest/panels/visualizations/time-series/
+type TimeSeriesPanel struct {
+	// How the series are drawn: line, bars or points
+	// +kubebuilder:validation:Enum=line;bars;points
+	DrawStyle string `json:"drawStyle,omitempty"`
+	// How the points of a line are joined: linear, smooth, stepBefore or stepAfter
+	// +kubebuilder:validation:Enum=linear;smooth;stepBefore;stepAfter
+	LineInterpolation string `json:"lineInterpolation,omitempty"`
+	// Opacity of the area under the series, from 0 to 100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	FillOpacity int32 `json:"fillOpacity,omitempty"`
+	// Stack the series: none, normal or percent
+	// +kubebuilder:validation:Enum=none;normal;percent
+	StackingMode string `json:"stackingMode,omitempty"`
+	// Where the Y-axis is shown: auto, left, right or hidden
+	// +kubebuilder:validation:Enum=auto;left;right;hidden
+	AxisPlacement string `json:"axisPlacement,omitempty"`
+}
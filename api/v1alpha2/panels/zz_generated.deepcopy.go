@@ -20,11 +20,19 @@ limitations under the License.
 
 package panels
 
-import ()
+import (
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+)
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Axis) DeepCopyInto(out *Axis) {
 	*out = *in
+	if in.Show != nil {
+		in, out := &in.Show, &out.Show
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Axis.
@@ -72,6 +80,56 @@ func (in *BarGaugePanel) DeepCopy() *BarGaugePanel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ColumnLink) DeepCopyInto(out *ColumnLink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ColumnLink.
+func (in *ColumnLink) DeepCopy() *ColumnLink {
+	if in == nil {
+		return nil
+	}
+	out := new(ColumnLink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ColumnStyle) DeepCopyInto(out *ColumnStyle) {
+	*out = *in
+	if in.Decimals != nil {
+		in, out := &in.Decimals, &out.Decimals
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Colors != nil {
+		in, out := &in.Colors, &out.Colors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Link != nil {
+		in, out := &in.Link, &out.Link
+		*out = new(ColumnLink)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ColumnStyle.
+func (in *ColumnStyle) DeepCopy() *ColumnStyle {
+	if in == nil {
+		return nil
+	}
+	out := new(ColumnStyle)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommonPanel) DeepCopyInto(out *CommonPanel) {
 	*out = *in
@@ -82,7 +140,7 @@ func (in *CommonPanel) DeepCopyInto(out *CommonPanel) {
 	}
 	if in.Datasource != nil {
 		in, out := &in.Datasource, &out.Datasource
-		*out = new(string)
+		*out = new(datasources.DatasourceRef)
 		**out = **in
 	}
 	if in.Height != nil {
@@ -90,6 +148,11 @@ func (in *CommonPanel) DeepCopyInto(out *CommonPanel) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.GridPos != nil {
+		in, out := &in.GridPos, &out.GridPos
+		*out = new(GridPos)
+		**out = **in
+	}
 	if in.Decimals != nil {
 		in, out := &in.Decimals, &out.Decimals
 		*out = new(int64)
@@ -98,7 +161,9 @@ func (in *CommonPanel) DeepCopyInto(out *CommonPanel) {
 	if in.Targets != nil {
 		in, out := &in.Targets, &out.Targets
 		*out = make([]Target, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Colors != nil {
 		in, out := &in.Colors, &out.Colors
@@ -107,8 +172,25 @@ func (in *CommonPanel) DeepCopyInto(out *CommonPanel) {
 	}
 	if in.Legend != nil {
 		in, out := &in.Legend, &out.Legend
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+		*out = new(Legend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReduceOptions != nil {
+		in, out := &in.ReduceOptions, &out.ReduceOptions
+		*out = new(ReduceOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FieldConfig != nil {
+		in, out := &in.FieldConfig, &out.FieldConfig
+		*out = new(FieldConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]links.Link, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -122,6 +204,110 @@ func (in *CommonPanel) DeepCopy() *CommonPanel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in CustomPanel) DeepCopyInto(out *CustomPanel) {
+	{
+		in := &in
+		*out = make(CustomPanel, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPanel.
+func (in CustomPanel) DeepCopy() CustomPanel {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomPanel)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldConfig) DeepCopyInto(out *FieldConfig) {
+	*out = *in
+	in.Defaults.DeepCopyInto(&out.Defaults)
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]FieldOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldConfig.
+func (in *FieldConfig) DeepCopy() *FieldConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldMatcher) DeepCopyInto(out *FieldMatcher) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldMatcher.
+func (in *FieldMatcher) DeepCopy() *FieldMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldOptions) DeepCopyInto(out *FieldOptions) {
+	*out = *in
+	if in.Decimals != nil {
+		in, out := &in.Decimals, &out.Decimals
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = new(Thresholds)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mappings != nil {
+		in, out := &in.Mappings, &out.Mappings
+		*out = make([]ValueMapping, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldOptions.
+func (in *FieldOptions) DeepCopy() *FieldOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldOverride) DeepCopyInto(out *FieldOverride) {
+	*out = *in
+	out.Matcher = in.Matcher
+	in.Properties.DeepCopyInto(&out.Properties)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldOverride.
+func (in *FieldOverride) DeepCopy() *FieldOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Gauge) DeepCopyInto(out *Gauge) {
 	*out = *in
@@ -137,14 +323,48 @@ func (in *Gauge) DeepCopy() *Gauge {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GaugePanel) DeepCopyInto(out *GaugePanel) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GaugePanel.
+func (in *GaugePanel) DeepCopy() *GaugePanel {
+	if in == nil {
+		return nil
+	}
+	out := new(GaugePanel)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GraphPanel) DeepCopyInto(out *GraphPanel) {
 	*out = *in
-	out.Xaxis = in.Xaxis
+	if in.Tooltip != nil {
+		in, out := &in.Tooltip, &out.Tooltip
+		*out = new(Tooltip)
+		**out = **in
+	}
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = make([]GraphThreshold, len(*in))
+		copy(*out, *in)
+	}
+	if in.SeriesOverrides != nil {
+		in, out := &in.SeriesOverrides, &out.SeriesOverrides
+		*out = make([]SeriesOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Xaxis.DeepCopyInto(&out.Xaxis)
 	if in.Yaxes != nil {
 		in, out := &in.Yaxes, &out.Yaxes
 		*out = make([]Axis, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -158,6 +378,92 @@ func (in *GraphPanel) DeepCopy() *GraphPanel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraphThreshold) DeepCopyInto(out *GraphThreshold) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphThreshold.
+func (in *GraphThreshold) DeepCopy() *GraphThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(GraphThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GridPos) DeepCopyInto(out *GridPos) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GridPos.
+func (in *GridPos) DeepCopy() *GridPos {
+	if in == nil {
+		return nil
+	}
+	out := new(GridPos)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeatmapColor) DeepCopyInto(out *HeatmapColor) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeatmapColor.
+func (in *HeatmapColor) DeepCopy() *HeatmapColor {
+	if in == nil {
+		return nil
+	}
+	out := new(HeatmapColor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeatmapPanel) DeepCopyInto(out *HeatmapPanel) {
+	*out = *in
+	if in.Color != nil {
+		in, out := &in.Color, &out.Color
+		*out = new(HeatmapColor)
+		**out = **in
+	}
+	in.YAxis.DeepCopyInto(&out.YAxis)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeatmapPanel.
+func (in *HeatmapPanel) DeepCopy() *HeatmapPanel {
+	if in == nil {
+		return nil
+	}
+	out := new(HeatmapPanel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Legend) DeepCopyInto(out *Legend) {
+	*out = *in
+	if in.Calcs != nil {
+		in, out := &in.Calcs, &out.Calcs
+		*out = make([]Calc, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Legend.
+func (in *Legend) DeepCopy() *Legend {
+	if in == nil {
+		return nil
+	}
+	out := new(Legend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Panel) DeepCopyInto(out *Panel) {
 	*out = *in
@@ -167,6 +473,11 @@ func (in *Panel) DeepCopyInto(out *Panel) {
 		*out = new(GraphPanel)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RowPanel != nil {
+		in, out := &in.RowPanel, &out.RowPanel
+		*out = new(RowPanel)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SinglestatPanel != nil {
 		in, out := &in.SinglestatPanel, &out.SinglestatPanel
 		*out = new(SinglestatPanel)
@@ -187,6 +498,31 @@ func (in *Panel) DeepCopyInto(out *Panel) {
 		*out = new(BarGaugePanel)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TimeSeriesPanel != nil {
+		in, out := &in.TimeSeriesPanel, &out.TimeSeriesPanel
+		*out = new(TimeSeriesPanel)
+		**out = **in
+	}
+	if in.StatPanel != nil {
+		in, out := &in.StatPanel, &out.StatPanel
+		*out = new(StatPanel)
+		**out = **in
+	}
+	if in.GaugePanel != nil {
+		in, out := &in.GaugePanel, &out.GaugePanel
+		*out = new(GaugePanel)
+		**out = **in
+	}
+	if in.HeatmapPanel != nil {
+		in, out := &in.HeatmapPanel, &out.HeatmapPanel
+		*out = new(HeatmapPanel)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Custom != nil {
+		in, out := &in.Custom, &out.Custom
+		*out = make(CustomPanel, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Panel.
@@ -199,9 +535,40 @@ func (in *Panel) DeepCopy() *Panel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReduceOptions) DeepCopyInto(out *ReduceOptions) {
+	*out = *in
+	if in.Calcs != nil {
+		in, out := &in.Calcs, &out.Calcs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReduceOptions.
+func (in *ReduceOptions) DeepCopy() *ReduceOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ReduceOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RowPanel) DeepCopyInto(out *RowPanel) {
 	*out = *in
+	if in.Panels != nil {
+		in, out := &in.Panels, &out.Panels
+		*out = make([]*Panel, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Panel)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RowPanel.
@@ -214,6 +581,56 @@ func (in *RowPanel) DeepCopy() *RowPanel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeriesOverride) DeepCopyInto(out *SeriesOverride) {
+	*out = *in
+	if in.Fill != nil {
+		in, out := &in.Fill, &out.Fill
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LineWidth != nil {
+		in, out := &in.LineWidth, &out.LineWidth
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Bars != nil {
+		in, out := &in.Bars, &out.Bars
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Lines != nil {
+		in, out := &in.Lines, &out.Lines
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Dashes != nil {
+		in, out := &in.Dashes, &out.Dashes
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Stack != nil {
+		in, out := &in.Stack, &out.Stack
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Legend != nil {
+		in, out := &in.Legend, &out.Legend
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeriesOverride.
+func (in *SeriesOverride) DeepCopy() *SeriesOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(SeriesOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SinglestatPanel) DeepCopyInto(out *SinglestatPanel) {
 	*out = *in
@@ -245,6 +662,21 @@ func (in *Sort) DeepCopy() *Sort {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatPanel) DeepCopyInto(out *StatPanel) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatPanel.
+func (in *StatPanel) DeepCopy() *StatPanel {
+	if in == nil {
+		return nil
+	}
+	out := new(StatPanel)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TablePanel) DeepCopyInto(out *TablePanel) {
 	*out = *in
@@ -253,6 +685,25 @@ func (in *TablePanel) DeepCopyInto(out *TablePanel) {
 		*out = new(Sort)
 		**out = **in
 	}
+	if in.Styles != nil {
+		in, out := &in.Styles, &out.Styles
+		*out = make([]ColumnStyle, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HiddenColumns != nil {
+		in, out := &in.HiddenColumns, &out.HiddenColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = make([]Transformation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TablePanel.
@@ -268,6 +719,11 @@ func (in *TablePanel) DeepCopy() *TablePanel {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Target) DeepCopyInto(out *Target) {
 	*out = *in
+	if in.Datasource != nil {
+		in, out := &in.Datasource, &out.Datasource
+		*out = new(datasources.DatasourceRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
@@ -294,3 +750,144 @@ func (in *TextPanel) DeepCopy() *TextPanel {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThresholdStep) DeepCopyInto(out *ThresholdStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThresholdStep.
+func (in *ThresholdStep) DeepCopy() *ThresholdStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ThresholdStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Thresholds) DeepCopyInto(out *Thresholds) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ThresholdStep, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Thresholds.
+func (in *Thresholds) DeepCopy() *Thresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(Thresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeSeriesPanel) DeepCopyInto(out *TimeSeriesPanel) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeSeriesPanel.
+func (in *TimeSeriesPanel) DeepCopy() *TimeSeriesPanel {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeSeriesPanel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Tooltip) DeepCopyInto(out *Tooltip) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Tooltip.
+func (in *Tooltip) DeepCopy() *Tooltip {
+	if in == nil {
+		return nil
+	}
+	out := new(Tooltip)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Transformation) DeepCopyInto(out *Transformation) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(TransformationOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Transformation.
+func (in *Transformation) DeepCopy() *Transformation {
+	if in == nil {
+		return nil
+	}
+	out := new(Transformation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationOptions) DeepCopyInto(out *TransformationOptions) {
+	*out = *in
+	if in.ExcludeByName != nil {
+		in, out := &in.ExcludeByName, &out.ExcludeByName
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IndexByName != nil {
+		in, out := &in.IndexByName, &out.IndexByName
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RenameByName != nil {
+		in, out := &in.RenameByName, &out.RenameByName
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Reducers != nil {
+		in, out := &in.Reducers, &out.Reducers
+		*out = make([]Calc, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationOptions.
+func (in *TransformationOptions) DeepCopy() *TransformationOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueMapping) DeepCopyInto(out *ValueMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueMapping.
+func (in *ValueMapping) DeepCopy() *ValueMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ValueMapping)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -2,104 +2,136 @@
 
 package templatings
 
-//referers to https://pkg.go.dev/github.com/K-Phoen/grabana/decoder#DashboardVariable
-// type Templatings struct {
-// 	Interval   *VariableInterval   `json:"interval,omitempty"`
-// 	Custom     *VariableCustom     `json:"custom,omitempty"`
-// 	Query      *VariableQuery      `json:"query,omitempty"`
-// 	Const      *VariableConst      `json:"const,omitempty"`
-// 	Datasource *VariableDatasource `json:"datasource,omitempty"`
-// }
-
-// type VariableInterval struct {
-// 	Name    string   `json:"name,omitempty"`
-// 	Label   string   `json:"label,omitempty"`
-// 	Default string   `json:"default,omitempty"`
-// 	Values  []string `json:"values,omitempty,flow"`
-// }
-
-// type VariableCustom struct {
-// 	Name       string            `json:"name,omitempty"`
-// 	Label      string            `json:"label,omitempty"`
-// 	Default    string            `json:"default,omitempty"`
-// 	ValuesMap  map[string]string `json:"values_map,omitempty"`
-// 	IncludeAll bool              `json:"include_all,omitempty"`
-// 	AllValue   string            `json:"all_value,omitempty"`
-// }
-
-// type VariableConst struct {
-// 	Name      string            `json:"name,omitempty"`
-// 	Label     string            `json:"label,omitempty"`
-// 	Default   string            `json:"default,omitempty"`
-// 	ValuesMap map[string]string `json:"values_map,omitempty"`
-// }
-
-// type VariableQuery struct {
-// 	Name       string `json:"name,omitempty"`
-// 	Label      string `json:"label,omitempty"`
-// 	Datasource string `json:"datasource,omitempty"`
-// 	Request    string `json:"request,omitempty"`
-// 	Regex      string `json:"regex,omitempty"`
-// 	IncludeAll bool   `json:"include_all,omitempty"`
-// 	DefaultAll bool   `json:"default_all,omitempty"`
-// 	AllValue   string `json:"all_value,omitempty"`
-// }
-
-// type VariableDatasource struct {
-// 	Name       string `json:"name,omitempty"`
-// 	Label      string `json:"label,omitempty"`
-// 	Query       string `json:"query,omitempty"`
-// 	Regex      string `json:"regex,omitempty"`
-// 	IncludeAll bool   `json:"include_all,omitempty"`
-// }
-
-// type TemplateVar struct {
-// 	// common properties, more than one containing
-// 	Name       string            `json:"name,omitempty"`
-// 	Type       string            `json:"type,omitempty"`
-// 	Auto       bool              `json:"auto,omitempty"`
-// 	AutoCount  *int              `json:"auto_count,omitempty"`
-// 	Label      string            `json:"label,omitempty"`
-// 	Default    string            `json:"default,omitempty"`
-// 	ValuesMap  map[string]string `json:"values_map,omitempty"`
-// 	IncludeAll bool              `json:"include_all,omitempty"`
-// 	AllValue   string            `json:"all_value,omitempty"`
-// 	Regex      string            `json:"regex,omitempty"`
-// 	// from type VariableInterval
-// 	Values []string `json:"values,omitempty,flow"`
-
-// 	// type VariableCustom has no special field
-// 	// type VariableConst has no special field
-
-// 	// from type VariableQuery
-// 	Datasource string `json:"datasource,omitempty"`
-// 	Request    string `json:"request,omitempty"`
-// 	DefaultAll bool   `json:"default_all,omitempty"`
-
-// 	// from type VariableDatasource
-// 	Query string `json:"query,omitempty"`
-// }
-
-// Refers to https://pkg.go.dev/github.com/grafana-tools/sdk#Templating
-//TemplateVar combines above types to one struct
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
 
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+)
+
+// Kinds of template variables
+const (
+	TypeQuery      = "query"
+	TypeCustom     = "custom"
+	TypeInterval   = "interval"
+	TypeConstant   = "constant"
+	TypeDatasource = "datasource"
+	TypeTextbox    = "textbox"
+	TypeAdhoc      = "adhoc"
+)
+
+// Refers to https://grafana.com/docs/grafana/latest/variables/variable-types/
+// TemplateVar is a variable of one of the kinds above: the options of its kind are
+// set in the matching field, and serialized inline with the common ones.
 type TemplateVar struct {
-	Name        string   `json:"name,omitempty"`
-	Type        string   `json:"type,omitempty"`
-	Auto        bool     `json:"auto,omitempty"`
-	AutoCount   *int     `json:"auto_count,omitempty"`
-	Datasource  *string  `json:"datasource,omitempty"`
-	Options     []Option `json:"options,omitempty"`
-	IncludeAll  bool     `json:"includeAll,omitempty"`
-	AllFormat   string   `json:"allFormat,omitempty"`
-	AllValue    string   `json:"allValue,omitempty"`
-	Multi       bool     `json:"multi,omitempty"`
-	MultiFormat string   `json:"multiFormat,omitempty"`
-	Query       string   `json:"query,omitempty"`
-	Regex       string   `json:"regex,omitempty"`
-	Label       string   `json:"label,omitempty"`
-	Hide        uint8    `json:"hide,omitempty"`
-	Sort        int      `json:"sort,omitempty"`
+	CommonVariable      `json:",inline"`
+	*QueryVariable      `json:",inline"`
+	*CustomVariable     `json:",inline"`
+	*IntervalVariable   `json:",inline"`
+	*ConstantVariable   `json:",inline"`
+	*DatasourceVariable `json:",inline"`
+	*TextboxVariable    `json:",inline"`
+	*AdhocVariable      `json:",inline"`
+}
+
+// Options shared by all kinds of variables
+type CommonVariable struct {
+	// Variable name, used as $name in queries
+	Name string `json:"name,omitempty"`
+	// Kind of the variable
+	// +kubebuilder:validation:Enum=query;custom;interval;constant;datasource;textbox;adhoc
+	Type string `json:"type,omitempty"`
+	// Name shown in the variable picker
+	Label string `json:"label,omitempty"`
+	// Hide the label (1) or the whole variable (2) from the dashboard
+	Hide uint8 `json:"hide,omitempty"`
+	// Datasource the query of a query variable is sent to, or the filters of an adhoc variable apply to
+	Datasource *datasources.DatasourceRef `json:"datasource,omitempty"`
+}
+
+// Options of the variables that may hold several values
+type MultiValue struct {
+	// Allow selecting several values
+	Multi bool `json:"multi,omitempty"`
+	// Offer an option selecting all values
+	IncludeAll bool `json:"includeAll,omitempty"`
+	// Value of the all option, all the values joined when empty
+	AllValue string `json:"allValue,omitempty"`
+}
+
+// QueryVariable takes its values from the result of a query
+type QueryVariable struct {
+	// Query such as label_values(kube_pod_info, namespace)
+	Query string `json:"query,omitempty"`
+	// Regular expression keeping, or extracting with a group, the values from the query result
+	Regex string `json:"regex,omitempty"`
+	// Order of the values: 0 as returned, 1 and 2 alphabetical, 3 and 4 numerical,
+	// 5 and 6 alphabetical case-insensitive, ascending then descending
+	Sort int `json:"sort,omitempty"`
+	// How the values are formatted when all of them are used
+	AllFormat string `json:"allFormat,omitempty"`
+	// How the values are formatted when several of them are used
+	MultiFormat string `json:"multiFormat,omitempty"`
+
+	MultiValue `json:",inline"`
+}
+
+// CustomVariable takes its values from a fixed list
+type CustomVariable struct {
+	// Values offered by the variable
+	Options []Option `json:"options,omitempty"`
+
+	MultiValue `json:",inline"`
+}
+
+// IntervalVariable takes a time span as value, to use as the step or range of a query
+type IntervalVariable struct {
+	// Time spans offered, such as 1m or 1h
+	Intervals []string `json:"intervals,omitempty"`
+	// Offer an auto option, dividing the time range of the dashboard in AutoCount steps
+	Auto bool `json:"auto,omitempty"`
+	// Number of steps of the auto option
+	AutoCount *int `json:"auto_count,omitempty"`
+}
+
+// ConstantVariable holds a hidden value, shared by the queries of the dashboard
+type ConstantVariable struct {
+	// The value
+	Value string `json:"value,omitempty"`
+}
+
+// DatasourceVariable takes the datasources of a type as values
+type DatasourceVariable struct {
+	// Type of the datasources, such as prometheus
+	PluginID string `json:"pluginId,omitempty"`
+	// Regular expression the datasource names must match
+	Regex string `json:"regex,omitempty"`
+
+	MultiValue `json:",inline"`
+}
+
+// TextboxVariable takes its value from a free text field
+type TextboxVariable struct {
+	// Value until a text is entered
+	Default string `json:"default,omitempty"`
+}
+
+// AdhocVariable adds label filters to every query sent to its datasource
+type AdhocVariable struct {
+	// Filters applied at first
+	Filters []AdhocFilter `json:"filters,omitempty"`
+}
+
+// AdhocFilter is a label matcher
+type AdhocFilter struct {
+	// Label name
+	Key string `json:"key"`
+	// Match operator
+	// +kubebuilder:validation:Enum==;!=;=~;!~
+	Operator string `json:"operator"`
+	// Label value, or regular expression for =~ and !~
+	Value string `json:"value,omitempty"`
 }
 
 type Option struct {
@@ -107,3 +139,133 @@ type Option struct {
 	Value    string `json:"value,omitempty"`
 	Selected bool   `json:"selected,omitempty"`
 }
+
+// the options of each kind of variable
+var kinds = map[string]func() interface{}{
+	TypeQuery:      func() interface{} { return &QueryVariable{} },
+	TypeCustom:     func() interface{} { return &CustomVariable{} },
+	TypeInterval:   func() interface{} { return &IntervalVariable{} },
+	TypeConstant:   func() interface{} { return &ConstantVariable{} },
+	TypeDatasource: func() interface{} { return &DatasourceVariable{} },
+	TypeTextbox:    func() interface{} { return &TextboxVariable{} },
+	TypeAdhoc:      func() interface{} { return &AdhocVariable{} },
+}
+
+// IsKnownType reports whether t is a kind of variable
+func IsKnownType(t string) bool {
+	_, ok := kinds[t]
+	return ok
+}
+
+func (v *TemplateVar) UnmarshalJSON(b []byte) error {
+	var probe struct {
+		CommonVariable
+		// variables used to be a single struct, keeping the values of
+		// every kind in the query as grafana does, and query variables
+		// could leave out their type
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return err
+	}
+	*v = TemplateVar{CommonVariable: probe.CommonVariable}
+	if v.Type == "" && probe.Query != "" {
+		v.Type = TypeQuery
+	}
+
+	factory, ok := kinds[v.Type]
+	if !ok {
+		return nil
+	}
+	options := factory()
+	if err := json.Unmarshal(b, options); err != nil {
+		return err
+	}
+	migrateQuery(options, probe.Query)
+	if value := reflect.ValueOf(options); !value.Elem().IsZero() {
+		v.kindField().Set(value)
+	}
+	return nil
+}
+
+func (v *TemplateVar) MarshalJSON() ([]byte, error) {
+	common, err := json.Marshal(v.CommonVariable)
+	if err != nil {
+		return nil, err
+	}
+	field := v.kindField()
+	if !field.IsValid() || field.IsNil() {
+		return common, nil
+	}
+	options, err := json.Marshal(field.Interface())
+	if err != nil {
+		return nil, err
+	}
+	if string(options) == "{}" {
+		return common, nil
+	}
+	if string(common) == "{}" {
+		return options, nil
+	}
+	return append(append(common[:len(common)-1:len(common)-1], ','), options[1:]...), nil
+}
+
+// kindField returns the field holding the options of the variable kind,
+// the zero Value for an unknown kind
+func (v *TemplateVar) kindField() reflect.Value {
+	factory, ok := kinds[v.Type]
+	if !ok {
+		return reflect.Value{}
+	}
+	t := reflect.TypeOf(factory())
+	value := reflect.ValueOf(v).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).Type() == t {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// migrateQuery moves the values the other kinds used to keep in the query to their own fields
+func migrateQuery(options interface{}, query string) {
+	if query == "" {
+		return
+	}
+	switch kind := options.(type) {
+	case *CustomVariable:
+		if len(kind.Options) == 0 {
+			for _, value := range SplitValues(query) {
+				kind.Options = append(kind.Options, Option{Text: value, Value: value})
+			}
+		}
+	case *IntervalVariable:
+		if len(kind.Intervals) == 0 {
+			kind.Intervals = SplitValues(query)
+		}
+	case *ConstantVariable:
+		if kind.Value == "" {
+			kind.Value = query
+		}
+	case *DatasourceVariable:
+		if kind.PluginID == "" {
+			kind.PluginID = query
+		}
+	case *TextboxVariable:
+		if kind.Default == "" {
+			kind.Default = query
+		}
+	}
+}
+
+// SplitValues splits a comma separated list of values, as grafana writes
+// the values of custom and interval variables
+func SplitValues(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,121 @@
+package templatings
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// namePattern matches the names usable as $name in a query
+var namePattern = regexp.MustCompile(`^[a-zA-Z_]\w*$`)
+
+// Validate checks the common options of the variable, then the options of its kind
+func (v *TemplateVar) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	switch {
+	case v.Name == "":
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	case !namePattern.MatchString(v.Name):
+		errs = append(errs, field.Invalid(path.Child("name"), v.Name, "must match "+namePattern.String()))
+	}
+
+	switch v.Type {
+	case TypeQuery:
+		errs = append(errs, v.QueryVariable.validate(path)...)
+	case TypeCustom:
+		errs = append(errs, v.CustomVariable.validate(path)...)
+	case TypeInterval:
+		errs = append(errs, v.IntervalVariable.validate(path)...)
+	case TypeConstant:
+		errs = append(errs, v.ConstantVariable.validate(path)...)
+	case TypeDatasource:
+		errs = append(errs, v.DatasourceVariable.validate(path)...)
+	case TypeTextbox:
+	case TypeAdhoc:
+		errs = append(errs, v.AdhocVariable.validate(path)...)
+	case "":
+		errs = append(errs, field.Required(path.Child("type"), ""))
+	default:
+		var supported []string
+		for kind := range kinds {
+			supported = append(supported, kind)
+		}
+		sort.Strings(supported)
+		errs = append(errs, field.NotSupported(path.Child("type"), v.Type, supported))
+	}
+
+	return errs
+}
+
+func (in *QueryVariable) validate(path *field.Path) field.ErrorList {
+	if in == nil || strings.TrimSpace(in.Query) == "" {
+		return field.ErrorList{field.Required(path.Child("query"), "a query variable needs a query")}
+	}
+	return nil
+}
+
+func (in *CustomVariable) validate(path *field.Path) field.ErrorList {
+	if in == nil || len(in.Options) == 0 {
+		return field.ErrorList{field.Required(path.Child("options"), "a custom variable needs options")}
+	}
+	var errs field.ErrorList
+	for i, option := range in.Options {
+		if option.Value == "" {
+			errs = append(errs, field.Required(path.Child("options").Index(i).Child("value"), ""))
+		}
+	}
+	return errs
+}
+
+func (in *IntervalVariable) validate(path *field.Path) field.ErrorList {
+	if in == nil || len(in.Intervals) == 0 {
+		return field.ErrorList{field.Required(path.Child("intervals"), "an interval variable needs intervals")}
+	}
+	var errs field.ErrorList
+	for i, interval := range in.Intervals {
+		if _, err := model.ParseDuration(interval); err != nil {
+			errs = append(errs, field.Invalid(path.Child("intervals").Index(i), interval, err.Error()))
+		}
+	}
+	if in.AutoCount != nil && *in.AutoCount <= 0 {
+		errs = append(errs, field.Invalid(path.Child("auto_count"), *in.AutoCount, "must be positive"))
+	}
+	return errs
+}
+
+func (in *ConstantVariable) validate(path *field.Path) field.ErrorList {
+	if in == nil || in.Value == "" {
+		return field.ErrorList{field.Required(path.Child("value"), "a constant variable needs a value")}
+	}
+	return nil
+}
+
+func (in *DatasourceVariable) validate(path *field.Path) field.ErrorList {
+	if in == nil || in.PluginID == "" {
+		return field.ErrorList{field.Required(path.Child("pluginId"), "a datasource variable needs a datasource type")}
+	}
+	return nil
+}
+
+func (in *AdhocVariable) validate(path *field.Path) field.ErrorList {
+	if in == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	for i, filter := range in.Filters {
+		if filter.Key == "" {
+			errs = append(errs, field.Required(path.Child("filters").Index(i).Child("key"), ""))
+		}
+		switch filter.Operator {
+		case "=", "!=", "=~", "!~":
+		default:
+			errs = append(errs, field.NotSupported(path.Child("filters").Index(i).Child("operator"), filter.Operator,
+				[]string{"=", "!=", "=~", "!~"}))
+		}
+	}
+	return errs
+}
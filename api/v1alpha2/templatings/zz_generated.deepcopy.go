@@ -20,41 +20,227 @@ limitations under the License.
 
 package templatings
 
-import ()
+import (
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+)
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Option) DeepCopyInto(out *Option) {
+func (in *AdhocFilter) DeepCopyInto(out *AdhocFilter) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Option.
-func (in *Option) DeepCopy() *Option {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdhocFilter.
+func (in *AdhocFilter) DeepCopy() *AdhocFilter {
 	if in == nil {
 		return nil
 	}
-	out := new(Option)
+	out := new(AdhocFilter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TemplateVar) DeepCopyInto(out *TemplateVar) {
+func (in *AdhocVariable) DeepCopyInto(out *AdhocVariable) {
 	*out = *in
-	if in.AutoCount != nil {
-		in, out := &in.AutoCount, &out.AutoCount
-		*out = new(int)
-		**out = **in
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = make([]AdhocFilter, len(*in))
+		copy(*out, *in)
 	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdhocVariable.
+func (in *AdhocVariable) DeepCopy() *AdhocVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(AdhocVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommonVariable) DeepCopyInto(out *CommonVariable) {
+	*out = *in
 	if in.Datasource != nil {
 		in, out := &in.Datasource, &out.Datasource
-		*out = new(string)
+		*out = new(datasources.DatasourceRef)
 		**out = **in
 	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonVariable.
+func (in *CommonVariable) DeepCopy() *CommonVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(CommonVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConstantVariable) DeepCopyInto(out *ConstantVariable) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConstantVariable.
+func (in *ConstantVariable) DeepCopy() *ConstantVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(ConstantVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomVariable) DeepCopyInto(out *CustomVariable) {
+	*out = *in
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make([]Option, len(*in))
 		copy(*out, *in)
 	}
+	out.MultiValue = in.MultiValue
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomVariable.
+func (in *CustomVariable) DeepCopy() *CustomVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatasourceVariable) DeepCopyInto(out *DatasourceVariable) {
+	*out = *in
+	out.MultiValue = in.MultiValue
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasourceVariable.
+func (in *DatasourceVariable) DeepCopy() *DatasourceVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(DatasourceVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntervalVariable) DeepCopyInto(out *IntervalVariable) {
+	*out = *in
+	if in.Intervals != nil {
+		in, out := &in.Intervals, &out.Intervals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoCount != nil {
+		in, out := &in.AutoCount, &out.AutoCount
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntervalVariable.
+func (in *IntervalVariable) DeepCopy() *IntervalVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(IntervalVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiValue) DeepCopyInto(out *MultiValue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiValue.
+func (in *MultiValue) DeepCopy() *MultiValue {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Option) DeepCopyInto(out *Option) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Option.
+func (in *Option) DeepCopy() *Option {
+	if in == nil {
+		return nil
+	}
+	out := new(Option)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryVariable) DeepCopyInto(out *QueryVariable) {
+	*out = *in
+	out.MultiValue = in.MultiValue
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryVariable.
+func (in *QueryVariable) DeepCopy() *QueryVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateVar) DeepCopyInto(out *TemplateVar) {
+	*out = *in
+	in.CommonVariable.DeepCopyInto(&out.CommonVariable)
+	if in.QueryVariable != nil {
+		in, out := &in.QueryVariable, &out.QueryVariable
+		*out = new(QueryVariable)
+		**out = **in
+	}
+	if in.CustomVariable != nil {
+		in, out := &in.CustomVariable, &out.CustomVariable
+		*out = new(CustomVariable)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IntervalVariable != nil {
+		in, out := &in.IntervalVariable, &out.IntervalVariable
+		*out = new(IntervalVariable)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConstantVariable != nil {
+		in, out := &in.ConstantVariable, &out.ConstantVariable
+		*out = new(ConstantVariable)
+		**out = **in
+	}
+	if in.DatasourceVariable != nil {
+		in, out := &in.DatasourceVariable, &out.DatasourceVariable
+		*out = new(DatasourceVariable)
+		**out = **in
+	}
+	if in.TextboxVariable != nil {
+		in, out := &in.TextboxVariable, &out.TextboxVariable
+		*out = new(TextboxVariable)
+		**out = **in
+	}
+	if in.AdhocVariable != nil {
+		in, out := &in.AdhocVariable, &out.AdhocVariable
+		*out = new(AdhocVariable)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateVar.
@@ -66,3 +252,18 @@ func (in *TemplateVar) DeepCopy() *TemplateVar {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TextboxVariable) DeepCopyInto(out *TextboxVariable) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TextboxVariable.
+func (in *TextboxVariable) DeepCopy() *TextboxVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(TextboxVariable)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,254 @@
+package time
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	gotime "time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Timezones of a dashboard, besides the IANA names such as Asia/Shanghai
+const (
+	// The timezone of the viewer
+	TimezoneBrowser = "browser"
+	// Coordinated Universal Time
+	TimezoneUTC = "utc"
+)
+
+// Layouts of the absolute times, which are in the timezone of the dashboard unless they tell theirs
+var absoluteLayouts = []string{
+	gotime.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// relativePattern matches a time relative to now: additions such as -1d or +2h,
+// then rounding to the start of a unit such as /d
+var (
+	relativePattern = regexp.MustCompile(`^now((?:[+-][0-9]+[smhdwMy])*)(?:/([smhdwMy]))?$`)
+	offsetPattern   = regexp.MustCompile(`([+-])([0-9]+)([smhdwMy])`)
+)
+
+// shiftPattern matches a time shift, or the relative time of a panel: a length of time
+// such as 1w, then rounding to the start of a unit such as /d
+var shiftPattern = regexp.MustCompile(`^[0-9]+[smhdwMy](?:/[smhdwMy])?$`)
+
+// LoadLocation returns the location of a dashboard timezone.
+// The timezone of the viewer isn't known to the server, the local one is used for browser and the empty timezone.
+func LoadLocation(timezone string) (*gotime.Location, error) {
+	switch strings.ToLower(timezone) {
+	case "", TimezoneBrowser:
+		return gotime.Local, nil
+	case TimezoneUTC:
+		return gotime.UTC, nil
+	}
+	return gotime.LoadLocation(timezone)
+}
+
+// Parse resolves a time of a range to an instant. It is either relative to now, such as now-1h,
+// now/d or now-1d/d, an RFC 3339 or ISO 8601 date and time, or a unix timestamp in milliseconds.
+// Days, weeks, months and years are calendar units of the timezone of now: now-1M is the same day
+// of the previous month. Rounding goes to the start of the unit, or to its end when roundUp is set,
+// as for the end of a range, so that now/d to now/d is the whole current day.
+func Parse(s string, now gotime.Time, roundUp bool) (gotime.Time, error) {
+	if m := relativePattern.FindStringSubmatch(s); m != nil {
+		t := now
+		for _, offset := range offsetPattern.FindAllStringSubmatch(m[1], -1) {
+			n, err := strconv.Atoi(offset[2])
+			if err != nil {
+				return gotime.Time{}, fmt.Errorf("invalid time %q: %v", s, err)
+			}
+			if offset[1] == "-" {
+				n = -n
+			}
+			t = add(t, n, offset[3][0])
+		}
+		if m[2] != "" {
+			t = round(t, m[2][0], roundUp)
+		}
+		return t, nil
+	}
+
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return gotime.Unix(0, ms*int64(gotime.Millisecond)).In(now.Location()), nil
+	}
+	for _, layout := range absoluteLayouts {
+		if t, err := gotime.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return gotime.Time{}, fmt.Errorf("invalid time %q: must be relative to now such as now-1h or now/d, a date and time or a unix timestamp in milliseconds", s)
+}
+
+// Resolve resolves the range to instants in the given dashboard timezone.
+// Empty bounds default to now.
+func (in Time) Resolve(now gotime.Time, timezone string) (from gotime.Time, to gotime.Time, err error) {
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+	now = now.In(loc)
+
+	from, to = now, now
+	if in.From != "" {
+		if from, err = Parse(in.From, now, false); err != nil {
+			return from, to, err
+		}
+	}
+	if in.To != "" {
+		if to, err = Parse(in.To, now, true); err != nil {
+			return from, to, err
+		}
+	}
+	return from, to, nil
+}
+
+// Override returns the range of a panel with its own relative time, such as 24h for the last 24 hours
+// or now/d for today so far, which ends now. Like grafana, the range of the dashboard is kept when it is
+// absolute, or when timeFrom is empty.
+func (in Time) Override(timeFrom string) (Time, error) {
+	if timeFrom == "" || (in.From != "" && !relativePattern.MatchString(in.From)) {
+		return in, nil
+	}
+	switch {
+	case shiftPattern.MatchString(timeFrom):
+		return Time{From: "now-" + timeFrom, To: "now"}, nil
+	case relativePattern.MatchString(timeFrom):
+		return Time{From: timeFrom, To: "now"}, nil
+	}
+	return in, fmt.Errorf("invalid relative time %q: must be a length of time such as 24h, or relative to now such as now/d", timeFrom)
+}
+
+// Shift moves t back by a time shift, such as 1w for the same time last week, or 1d/d for the start
+// of the previous day, or its end when roundUp is set, as for the end of a range
+func Shift(t gotime.Time, shift string, roundUp bool) (gotime.Time, error) {
+	if shift == "" {
+		return t, nil
+	}
+	if !shiftPattern.MatchString(shift) {
+		return t, fmt.Errorf("invalid time shift %q: must be a length of time such as 1w, optionally rounded such as 1d/d", shift)
+	}
+	return Parse("now-"+shift, t, roundUp)
+}
+
+// ResolvePanel resolves the range of a panel: the range of the dashboard, or the relative time of
+// the panel, moved back by the time shift of the panel
+func (in Time) ResolvePanel(now gotime.Time, timezone, timeFrom, timeShift string) (from gotime.Time, to gotime.Time, err error) {
+	t, err := in.Override(timeFrom)
+	if err != nil {
+		return from, to, err
+	}
+	if from, to, err = t.Resolve(now, timezone); err != nil {
+		return from, to, err
+	}
+	if from, err = Shift(from, timeShift, false); err != nil {
+		return from, to, err
+	}
+	to, err = Shift(to, timeShift, true)
+	return from, to, err
+}
+
+// add adds n units to t, days and longer units following the calendar
+func add(t gotime.Time, n int, unit byte) gotime.Time {
+	switch unit {
+	case 's':
+		return t.Add(gotime.Duration(n) * gotime.Second)
+	case 'm':
+		return t.Add(gotime.Duration(n) * gotime.Minute)
+	case 'h':
+		return t.Add(gotime.Duration(n) * gotime.Hour)
+	case 'd':
+		return t.AddDate(0, 0, n)
+	case 'w':
+		return t.AddDate(0, 0, 7*n)
+	case 'M':
+		return addMonths(t, n)
+	case 'y':
+		return addMonths(t, 12*n)
+	}
+	return t
+}
+
+// addMonths adds n months to t, keeping the day within the month: a month before March 31st is February 28th or 29th
+func addMonths(t gotime.Time, n int) gotime.Time {
+	year, month, day := t.Date()
+	first := gotime.Date(year, month+gotime.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// round rounds t down to the start of the unit, or up to its last millisecond.
+// Weeks start on Monday.
+func round(t gotime.Time, unit byte, roundUp bool) gotime.Time {
+	year, month, day := t.Date()
+	loc := t.Location()
+
+	var start gotime.Time
+	switch unit {
+	case 's':
+		start = t.Truncate(gotime.Second)
+	case 'm':
+		start = gotime.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, loc)
+	case 'h':
+		start = gotime.Date(year, month, day, t.Hour(), 0, 0, 0, loc)
+	case 'd':
+		start = gotime.Date(year, month, day, 0, 0, 0, 0, loc)
+	case 'w':
+		start = gotime.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case 'M':
+		start = gotime.Date(year, month, 1, 0, 0, 0, 0, loc)
+	case 'y':
+		start = gotime.Date(year, gotime.January, 1, 0, 0, 0, 0, loc)
+	}
+	if !roundUp {
+		return start
+	}
+	return add(start, 1, unit).Add(-gotime.Millisecond)
+}
+
+// Validate checks that the bounds of the range resolve in the dashboard timezone, and that the range
+// ends after it starts. An invalid timezone is left to the caller, the bounds are resolved in UTC then.
+func (in Time) Validate(timezone string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if _, err := LoadLocation(timezone); err != nil {
+		timezone = TimezoneUTC
+	}
+	now := gotime.Now()
+	from, _, err := Time{From: in.From}.Resolve(now, timezone)
+	if err != nil {
+		errs = append(errs, field.Invalid(path.Child("from"), in.From, err.Error()))
+	}
+	_, to, err := Time{To: in.To}.Resolve(now, timezone)
+	if err != nil {
+		errs = append(errs, field.Invalid(path.Child("to"), in.To, err.Error()))
+	}
+
+	if len(errs) == 0 && in.From != "" && in.To != "" && from.After(to) {
+		errs = append(errs, field.Invalid(path.Child("from"), in.From, "must be before "+in.To))
+	}
+	return errs
+}
+
+// ValidateOverride checks the relative time and the time shift of a panel.
+// Those made of variables are only known once the variables are expanded.
+func ValidateOverride(timeFrom, timeShift string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if !strings.Contains(timeFrom, "$") {
+		if _, err := (Time{}).Override(timeFrom); err != nil {
+			errs = append(errs, field.Invalid(path.Child("timeFrom"), timeFrom, err.Error()))
+		}
+	}
+	if !strings.Contains(timeShift, "$") {
+		if _, err := Shift(gotime.Now(), timeShift, false); err != nil {
+			errs = append(errs, field.Invalid(path.Child("timeShift"), timeShift, err.Error()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,152 @@
+package time
+
+import (
+	"testing"
+	gotime "time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestParse(t *testing.T) {
+	shanghai, err := gotime.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	// a Wednesday
+	now := gotime.Date(2021, gotime.March, 31, 14, 30, 15, 0, gotime.UTC)
+
+	tests := []struct {
+		s       string
+		now     gotime.Time
+		roundUp bool
+		want    gotime.Time
+	}{
+		{s: "now", want: now},
+		{s: "now-1h", want: now.Add(-gotime.Hour)},
+		{s: "now+30m", want: now.Add(30 * gotime.Minute)},
+		{s: "now-1d-12h", want: gotime.Date(2021, gotime.March, 30, 2, 30, 15, 0, gotime.UTC)},
+		{s: "now-1w", want: gotime.Date(2021, gotime.March, 24, 14, 30, 15, 0, gotime.UTC)},
+		{s: "now-1M", want: gotime.Date(2021, gotime.February, 28, 14, 30, 15, 0, gotime.UTC)},
+		{s: "now-13M", want: gotime.Date(2020, gotime.February, 29, 14, 30, 15, 0, gotime.UTC)},
+		{s: "now-1y", want: gotime.Date(2020, gotime.March, 31, 14, 30, 15, 0, gotime.UTC)},
+		{s: "now/h", want: gotime.Date(2021, gotime.March, 31, 14, 0, 0, 0, gotime.UTC)},
+		{s: "now/d", want: gotime.Date(2021, gotime.March, 31, 0, 0, 0, 0, gotime.UTC)},
+		{s: "now/d", roundUp: true, want: gotime.Date(2021, gotime.March, 31, 23, 59, 59, 999000000, gotime.UTC)},
+		{s: "now-1d/d", want: gotime.Date(2021, gotime.March, 30, 0, 0, 0, 0, gotime.UTC)},
+		{s: "now/w", want: gotime.Date(2021, gotime.March, 29, 0, 0, 0, 0, gotime.UTC)},
+		{s: "now/M", want: gotime.Date(2021, gotime.March, 1, 0, 0, 0, 0, gotime.UTC)},
+		{s: "now/M", roundUp: true, want: gotime.Date(2021, gotime.March, 31, 23, 59, 59, 999000000, gotime.UTC)},
+		{s: "now-1y/y", want: gotime.Date(2020, gotime.January, 1, 0, 0, 0, 0, gotime.UTC)},
+		{s: "now/d", now: now.In(shanghai), want: gotime.Date(2021, gotime.March, 30, 16, 0, 0, 0, gotime.UTC)},
+		{s: "2021-06-01T08:00:00Z", want: gotime.Date(2021, gotime.June, 1, 8, 0, 0, 0, gotime.UTC)},
+		{s: "2021-06-01T08:00:00.000+08:00", want: gotime.Date(2021, gotime.June, 1, 0, 0, 0, 0, gotime.UTC)},
+		{s: "2021-06-01 08:00:00", now: now.In(shanghai), want: gotime.Date(2021, gotime.June, 1, 0, 0, 0, 0, gotime.UTC)},
+		{s: "2021-06-01", want: gotime.Date(2021, gotime.June, 1, 0, 0, 0, 0, gotime.UTC)},
+		{s: "1622534400000", want: gotime.Date(2021, gotime.June, 1, 8, 0, 0, 0, gotime.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			n := test.now
+			if n.IsZero() {
+				n = now
+			}
+			got, err := Parse(test.s, n, test.roundUp)
+			require.NoError(t, err)
+			require.True(t, test.want.Equal(got), "want %v, got %v", test.want, got)
+		})
+	}
+
+	for _, s := range []string{"", "yesterday", "now-", "now-1", "now-1x", "now/", "now/d-1h", "2021-13-01"} {
+		_, err := Parse(s, now, false)
+		require.Error(t, err, s)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	req := require.New(t)
+	now := gotime.Date(2021, gotime.March, 31, 20, 0, 0, 0, gotime.UTC)
+
+	from, to, err := Time{From: "now/d", To: "now/d"}.Resolve(now, "Asia/Shanghai")
+	req.NoError(err)
+	req.Equal("2021-04-01T00:00:00+08:00", from.Format(gotime.RFC3339))
+	req.Equal("2021-04-01T23:59:59+08:00", to.Format(gotime.RFC3339))
+
+	from, to, err = Time{From: "now-1h"}.Resolve(now, "UTC")
+	req.NoError(err)
+	req.Equal(gotime.Hour, to.Sub(from))
+
+	_, _, err = Time{From: "now-1h"}.Resolve(now, "Mars/Olympus")
+	req.Error(err)
+	_, _, err = Time{From: "now-1h", To: "tomorrow"}.Resolve(now, TimezoneBrowser)
+	req.Error(err)
+}
+
+func TestValidate(t *testing.T) {
+	req := require.New(t)
+	path := field.NewPath("time")
+
+	req.Empty(Time{}.Validate(TimezoneBrowser, path))
+	req.Empty(Time{From: "now-1d/d", To: "now-1d/d"}.Validate(TimezoneUTC, path))
+	req.Empty(Time{From: "now/w", To: "now/w"}.Validate("Asia/Shanghai", path))
+	req.Empty(Time{From: "2021-06-01", To: "now"}.Validate("Mars/Olympus", path))
+
+	errs := Time{From: "yesterday", To: "now+"}.Validate(TimezoneUTC, path)
+	req.Len(errs, 2)
+	req.Equal("time.from", errs[0].Field)
+	req.Equal("time.to", errs[1].Field)
+
+	errs = Time{From: "now", To: "now-1h"}.Validate("Asia/Shanghai", path)
+	req.Len(errs, 1)
+	req.Contains(errs[0].Error(), "must be before now-1h")
+}
+
+func TestResolvePanel(t *testing.T) {
+	now := gotime.Date(2021, gotime.March, 31, 20, 0, 0, 0, gotime.UTC)
+	last6h := Time{From: "now-6h", To: "now"}
+
+	tests := []struct {
+		name      string
+		time      Time
+		timeFrom  string
+		timeShift string
+		from, to  string
+	}{
+		{name: "dashboard range", time: last6h, from: "2021-03-31T14:00:00Z", to: "2021-03-31T20:00:00Z"},
+		{name: "relative time", time: last6h, timeFrom: "24h", from: "2021-03-30T20:00:00Z", to: "2021-03-31T20:00:00Z"},
+		{name: "today so far", time: last6h, timeFrom: "now/d", from: "2021-03-31T00:00:00Z", to: "2021-03-31T20:00:00Z"},
+		{name: "time shift", time: last6h, timeShift: "1w", from: "2021-03-24T14:00:00Z", to: "2021-03-24T20:00:00Z"},
+		{name: "yesterday", time: Time{From: "now/d", To: "now/d"}, timeShift: "1d/d", from: "2021-03-30T00:00:00Z", to: "2021-03-30T23:59:59Z"},
+		{name: "both", time: last6h, timeFrom: "2h", timeShift: "1d", from: "2021-03-30T18:00:00Z", to: "2021-03-30T20:00:00Z"},
+		{
+			name: "absolute dashboard range", time: Time{From: "2021-03-01", To: "2021-03-02"}, timeFrom: "24h", timeShift: "1d",
+			from: "2021-02-28T00:00:00Z", to: "2021-03-01T00:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := tt.time.ResolvePanel(now, TimezoneUTC, tt.timeFrom, tt.timeShift)
+			require.NoError(t, err)
+			require.Equal(t, tt.from, from.Format(gotime.RFC3339))
+			require.Equal(t, tt.to, to.Format(gotime.RFC3339))
+		})
+	}
+
+	_, _, err := last6h.ResolvePanel(now, TimezoneUTC, "yesterday", "")
+	require.Error(t, err)
+	_, _, err = last6h.ResolvePanel(now, TimezoneUTC, "", "now-1w")
+	require.Error(t, err)
+}
+
+func TestValidateOverride(t *testing.T) {
+	req := require.New(t)
+	path := field.NewPath("panels").Index(0)
+
+	req.Empty(ValidateOverride("", "", path))
+	req.Empty(ValidateOverride("7d", "1d/d", path))
+	req.Empty(ValidateOverride("now-1d/d", "$shift", path))
+
+	errs := ValidateOverride("last week", "-1w", path)
+	req.Len(errs, 2)
+	req.Equal("panels[0].timeFrom", errs[0].Field)
+	req.Equal("panels[0].timeShift", errs[1].Field)
+}
@@ -2,12 +2,13 @@
 
 package time
 
-// Time ranges of the metrics for display
+// Time ranges of the metrics for display, in the timezone of the dashboard.
+// A time is either relative to now, such as `now-1M` for the same day last month or `now-1d/d` for
+// the start of yesterday, a date and time such as `2021-06-01T00:00:00Z`, or a unix timestamp in milliseconds.
 type Time struct {
-	// Start time in the format of `^now([+-][0-9]+[smhdwMy])?$`, eg. `now-1M`.
-	// It denotes the end time is set to the last month since now.
-	From string `json:"from,omitempty" json:"from,omitempty"`
-	// End time in the format of `^now([+-][0-9]+[smhdwMy])?$`, eg. `now-1M`.
+	// Start time, eg. `now-1M`.
 	// It denotes the start time is set to the last month since now.
+	From string `json:"from,omitempty" json:"from,omitempty"`
+	// End time, eg. `now`. Rounding goes to the end of the unit, so `now/d` is the end of today.
 	To string `json:"to,omitempty" json:"to,omitempty"`
 }
@@ -21,8 +21,11 @@ limitations under the License.
 package v1alpha2
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	annotations "kubesphere.io/monitoring-dashboard/api/v1alpha2/annotations"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
 	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
 	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
 )
@@ -33,6 +36,7 @@ func (in *ClusterDashboard) DeepCopyInto(out *ClusterDashboard) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDashboard.
@@ -91,6 +95,7 @@ func (in *Dashboard) DeepCopyInto(out *Dashboard) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dashboard.
@@ -177,6 +182,20 @@ func (in *DashboardSpec) DeepCopyInto(out *DashboardSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]datasources.Input, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]links.Link, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSpec.
@@ -188,3 +207,45 @@ func (in *DashboardSpec) DeepCopy() *DashboardSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardStatus) DeepCopyInto(out *DashboardStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Problems != nil {
+		in, out := &in.Problems, &out.Problems
+		*out = make([]PanelProblem, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardStatus.
+func (in *DashboardStatus) DeepCopy() *DashboardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PanelProblem) DeepCopyInto(out *PanelProblem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PanelProblem.
+func (in *PanelProblem) DeepCopy() *PanelProblem {
+	if in == nil {
+		return nil
+	}
+	out := new(PanelProblem)
+	in.DeepCopyInto(out)
+	return out
+}
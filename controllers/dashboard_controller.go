@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
+)
+
+// DashboardReconciler reconciles a Dashboard object
+type DashboardReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=monitoring.kubesphere.io,resources=dashboards,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.kubesphere.io,resources=dashboards/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *DashboardReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("dashboard", req.NamespacedName)
+
+	var dashboard monitoringv1alpha2.Dashboard
+	if err := r.Get(ctx, req.NamespacedName, &dashboard); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	brokenLinks, err := linkProblems(ctx, r.Client, dashboard.Namespace, &dashboard.Spec)
+	if err != nil {
+		log.Error(err, "unable to check the links of the dashboard")
+		return ctrl.Result{}, err
+	}
+	// the dashboards the links point at may be created later
+	var result ctrl.Result
+	if len(brokenLinks) > 0 {
+		result.RequeueAfter = linkRecheckInterval
+	}
+
+	status := observeDashboard(&dashboard.Spec, dashboard.Generation, dashboard.Status, brokenLinks...)
+	if equality.Semantic.DeepEqual(status, dashboard.Status) {
+		return result, nil
+	}
+
+	recordProblems(r.Recorder, &dashboard, dashboard.Status, status)
+	dashboard.Status = status
+	if err := r.Status().Update(ctx, &dashboard); err != nil {
+		log.Error(err, "unable to update dashboard status")
+		return ctrl.Result{}, err
+	}
+
+	return result, nil
+}
+
+func (r *DashboardReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringv1alpha2.Dashboard{}).
+		Complete(r)
+}
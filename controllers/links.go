@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// linkRecheckInterval is how often the links of a dashboard are checked again while some are broken,
+// as the dashboards they point at may be created later
+const linkRecheckInterval = 5 * time.Minute
+
+// linkChecker checks that links point at existing dashboards, listing the dashboards at most once per kind
+type linkChecker struct {
+	client.Reader
+	// namespace of the dashboard holding the links, empty for a cluster dashboard
+	namespace string
+	// tags of the dashboards by kind, listed when first needed
+	tags map[string][][]string
+}
+
+// linkProblems checks that the links of the dashboard and of its panels point at existing dashboards:
+// dashboards of the same namespace or cluster dashboards by name, and at least one dashboard with the
+// tags of a link. Links whose dashboard name depends on a variable can't be checked.
+func linkProblems(ctx context.Context, reader client.Reader, namespace string, spec *monitoringv1alpha2.DashboardSpec) ([]monitoringv1alpha2.PanelProblem, error) {
+	checker := &linkChecker{Reader: reader, namespace: namespace, tags: map[string][][]string{}}
+
+	var problems []monitoringv1alpha2.PanelProblem
+	check := func(panel *panels.Panel, lks []links.Link) error {
+		for _, link := range lks {
+			message, err := checker.check(ctx, link)
+			if err != nil {
+				return err
+			}
+			if message == "" {
+				continue
+			}
+			problem := monitoringv1alpha2.PanelProblem{Reason: monitoringv1alpha2.ReasonBrokenLink, Message: message}
+			if panel != nil {
+				problem.PanelID = panel.Id
+				problem.PanelTitle = panel.Title
+			}
+			problems = append(problems, problem)
+		}
+		return nil
+	}
+
+	if err := check(nil, spec.Links); err != nil {
+		return nil, err
+	}
+	for _, panel := range panels.Flatten(spec.Panels) {
+		if err := check(panel, panel.Links); err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+// check describes why the link is broken, the empty string when it isn't
+func (c *linkChecker) check(ctx context.Context, link links.Link) (string, error) {
+	if link.Type != links.TypeDashboard && link.Type != links.TypeDashboards {
+		return "", nil
+	}
+
+	kind := link.Kind
+	if kind == "" {
+		kind = links.KindDashboard
+		if c.namespace == "" {
+			kind = links.KindClusterDashboard
+		}
+	}
+	if kind == links.KindDashboard && c.namespace == "" {
+		return fmt.Sprintf("link %q points at a Dashboard, cluster dashboards can only link to cluster dashboards", linkTitle(link)), nil
+	}
+
+	if link.Type == links.TypeDashboard {
+		if strings.Contains(link.Dashboard, "$") {
+			return "", nil
+		}
+		var object client.Object = &monitoringv1alpha2.ClusterDashboard{}
+		key := client.ObjectKey{Name: link.Dashboard}
+		if kind == links.KindDashboard {
+			object = &monitoringv1alpha2.Dashboard{}
+			key.Namespace = c.namespace
+		}
+		err := c.Get(ctx, key, object)
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("link %q points at %s %q, which does not exist", linkTitle(link), kind, link.Dashboard), nil
+		}
+		return "", err
+	}
+
+	tags, err := c.listTags(ctx, kind)
+	if err != nil {
+		return "", err
+	}
+	for _, dashboardTags := range tags {
+		if hasTags(dashboardTags, link.Tags) {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("link %q points at the %ss tagged %s, there are none", linkTitle(link), kind, strings.Join(link.Tags, ", ")), nil
+}
+
+// listTags lists the tags of the dashboards of a kind
+func (c *linkChecker) listTags(ctx context.Context, kind string) ([][]string, error) {
+	if tags, ok := c.tags[kind]; ok {
+		return tags, nil
+	}
+
+	var tags [][]string
+	if kind == links.KindDashboard {
+		var list monitoringv1alpha2.DashboardList
+		if err := c.List(ctx, &list, client.InNamespace(c.namespace)); err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			tags = append(tags, item.Spec.Tags)
+		}
+	} else {
+		var list monitoringv1alpha2.ClusterDashboardList
+		if err := c.List(ctx, &list); err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			tags = append(tags, item.Spec.Tags)
+		}
+	}
+	c.tags[kind] = tags
+	return tags, nil
+}
+
+// hasTags tells whether all the wanted tags are among the tags
+func hasTags(tags, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, tag := range tags {
+			if tag == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// linkTitle names a link by its title, else by the dashboard or the tags it points at
+func linkTitle(link links.Link) string {
+	switch {
+	case link.Title != "":
+		return link.Title
+	case link.Dashboard != "":
+		return link.Dashboard
+	}
+	return strings.Join(link.Tags, ", ")
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monitoringv1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+func TestLinkProblems(t *testing.T) {
+	req := require.New(t)
+
+	scheme := runtime.NewScheme()
+	req.NoError(monitoringv1alpha2.AddToScheme(scheme))
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&monitoringv1alpha2.Dashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: "default"},
+			Spec:       monitoringv1alpha2.DashboardSpec{Tags: []string{"kubernetes", "pods"}},
+		},
+		&monitoringv1alpha2.Dashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes", Namespace: "kube-system"},
+			Spec:       monitoringv1alpha2.DashboardSpec{Tags: []string{"nodes"}},
+		},
+		&monitoringv1alpha2.ClusterDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       monitoringv1alpha2.DashboardSpec{Tags: []string{"kubernetes"}},
+		},
+	).Build()
+
+	spec := &monitoringv1alpha2.DashboardSpec{
+		Links: []links.Link{
+			{Type: links.TypeDashboard, Dashboard: "pods"},
+			{Type: links.TypeDashboard, Dashboard: "cluster", Kind: links.KindClusterDashboard},
+			{Type: links.TypeDashboard, Dashboard: "$namespace-pods"},
+			{Type: links.TypeDashboards, Tags: []string{"kubernetes", "pods"}},
+			{Type: links.TypeLink, URL: "https://example.com"},
+			{Title: "Nodes", Type: links.TypeDashboard, Dashboard: "nodes"},
+			{Type: links.TypeDashboards, Tags: []string{"nodes"}},
+		},
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "row", Title: "Pods"}, RowPanel: &panels.RowPanel{Panels: []*panels.Panel{
+				{CommonPanel: panels.CommonPanel{Id: 2, Type: "graph", Title: "CPU", Links: []links.Link{
+					{Type: links.TypeDashboard, Dashboard: "pod", Kind: links.KindDashboard},
+				}}},
+			}}},
+		},
+	}
+
+	problems, err := linkProblems(context.Background(), reader, "default", spec)
+	req.NoError(err)
+	req.Equal([]monitoringv1alpha2.PanelProblem{{
+		Reason:  monitoringv1alpha2.ReasonBrokenLink,
+		Message: `link "Nodes" points at Dashboard "nodes", which does not exist`,
+	}, {
+		Reason:  monitoringv1alpha2.ReasonBrokenLink,
+		Message: `link "nodes" points at the Dashboards tagged nodes, there are none`,
+	}, {
+		PanelID:    2,
+		PanelTitle: "CPU",
+		Reason:     monitoringv1alpha2.ReasonBrokenLink,
+		Message:    `link "pod" points at Dashboard "pod", which does not exist`,
+	}}, problems)
+
+	problems, err = linkProblems(context.Background(), reader, "", &monitoringv1alpha2.DashboardSpec{
+		Links: []links.Link{
+			{Type: links.TypeDashboards, Tags: []string{"kubernetes"}},
+			{Type: links.TypeDashboard, Dashboard: "pods", Kind: links.KindDashboard},
+		},
+	})
+	req.NoError(err)
+	req.Equal([]monitoringv1alpha2.PanelProblem{{
+		Reason:  monitoringv1alpha2.ReasonBrokenLink,
+		Message: `link "pods" points at a Dashboard, cluster dashboards can only link to cluster dashboards`,
+	}}, problems)
+}
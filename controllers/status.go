@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The KubeSphere authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	monitoringv1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// Reasons used in the dashboard conditions and events
+const (
+	ReasonReconciled     = "Reconciled"
+	ReasonSpecValid      = "SpecValid"
+	ReasonSpecInvalid    = "SpecInvalid"
+	ReasonQueriesHealthy = "QueriesHealthy"
+	ReasonQueriesBroken  = "QueriesBroken"
+)
+
+// observeDashboard computes the observed state of a dashboard spec, with the problems found
+// outside of the spec, such as broken links, added to the ones of the spec.
+// Conditions are carried over from the current status so that their transition time
+// only changes when their status does.
+func observeDashboard(spec *monitoringv1alpha2.DashboardSpec, generation int64, current monitoringv1alpha2.DashboardStatus, problems ...monitoringv1alpha2.PanelProblem) monitoringv1alpha2.DashboardStatus {
+	status := monitoringv1alpha2.DashboardStatus{
+		ObservedGeneration: generation,
+		VariableCount:      int32(len(spec.Templatings)),
+		Problems:           append(spec.Validate(), problems...),
+	}
+	for i := range current.Conditions {
+		status.Conditions = append(status.Conditions, current.Conditions[i])
+	}
+
+	for _, panel := range panels.Flatten(spec.Panels) {
+		status.PanelCount++
+		status.TargetCount += int32(len(panel.Targets))
+	}
+
+	var invalid, broken []string
+	for _, problem := range status.Problems {
+		if problem.IsQueryProblem() {
+			broken = append(broken, problem.String())
+		} else {
+			invalid = append(invalid, problem.String())
+		}
+	}
+
+	if len(invalid) == 0 {
+		setCondition(&status, monitoringv1alpha2.DashboardValid, metav1.ConditionTrue, ReasonSpecValid, "all panels are valid")
+	} else {
+		setCondition(&status, monitoringv1alpha2.DashboardValid, metav1.ConditionFalse, ReasonSpecInvalid, strings.Join(invalid, "; "))
+	}
+
+	if len(broken) == 0 {
+		setCondition(&status, monitoringv1alpha2.DashboardQueriesHealthy, metav1.ConditionTrue, ReasonQueriesHealthy, "all targets have a usable expression")
+	} else {
+		setCondition(&status, monitoringv1alpha2.DashboardQueriesHealthy, metav1.ConditionFalse, ReasonQueriesBroken, strings.Join(broken, "; "))
+	}
+
+	setCondition(&status, monitoringv1alpha2.DashboardSynced, metav1.ConditionTrue, ReasonReconciled,
+		fmt.Sprintf("status reflects generation %d", generation))
+
+	return status
+}
+
+// recordProblems emits an event for every problem that was not reported before,
+// and a normal event once a broken dashboard became valid again
+func recordProblems(recorder record.EventRecorder, object runtime.Object, previous, current monitoringv1alpha2.DashboardStatus) {
+	reported := make(map[monitoringv1alpha2.PanelProblem]bool, len(previous.Problems))
+	for _, problem := range previous.Problems {
+		reported[problem] = true
+	}
+	for _, problem := range current.Problems {
+		if !reported[problem] {
+			recorder.Event(object, corev1.EventTypeWarning, problem.Reason, problem.String())
+		}
+	}
+	if len(previous.Problems) > 0 && len(current.Problems) == 0 {
+		recorder.Event(object, corev1.EventTypeNormal, ReasonSpecValid, "all problems of the dashboard are resolved")
+	}
+}
+
+func setCondition(status *monitoringv1alpha2.DashboardStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: status.ObservedGeneration,
+		Reason:             reason,
+		Message:            message,
+	})
+}
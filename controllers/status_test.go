@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	monitoringv1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+)
+
+func TestObserveHealthyDashboard(t *testing.T) {
+	req := require.New(t)
+
+	spec := &monitoringv1alpha2.DashboardSpec{
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "row", Title: "Overview"}},
+			{CommonPanel: panels.CommonPanel{Id: 2, Type: "graph", Title: "QPS", Targets: []panels.Target{
+				{RefID: 1, Expression: "sum(rate(http_requests_total[5m]))"},
+				{RefID: 2, Expression: "vector(1)"},
+			}}},
+			nil,
+		},
+		Templatings: []templatings.TemplateVar{{CommonVariable: templatings.CommonVariable{Name: "namespace"}}},
+	}
+
+	status := observeDashboard(spec, 3, monitoringv1alpha2.DashboardStatus{})
+
+	req.Equal(int64(3), status.ObservedGeneration)
+	req.Equal(int32(2), status.PanelCount)
+	req.Equal(int32(2), status.TargetCount)
+	req.Equal(int32(1), status.VariableCount)
+	req.Empty(status.Problems)
+	req.True(meta.IsStatusConditionTrue(status.Conditions, monitoringv1alpha2.DashboardValid))
+	req.True(meta.IsStatusConditionTrue(status.Conditions, monitoringv1alpha2.DashboardSynced))
+	req.True(meta.IsStatusConditionTrue(status.Conditions, monitoringv1alpha2.DashboardQueriesHealthy))
+}
+
+func TestObserveBrokenDashboard(t *testing.T) {
+	req := require.New(t)
+
+	spec := &monitoringv1alpha2.DashboardSpec{
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Title: "Untyped"}},
+			{CommonPanel: panels.CommonPanel{Id: 2, Type: "graph", Title: "QPS", Targets: []panels.Target{
+				{RefID: 1, Expression: " "},
+			}}},
+		},
+	}
+
+	status := observeDashboard(spec, 1, monitoringv1alpha2.DashboardStatus{})
+
+	req.Len(status.Problems, 2)
+	req.Equal(monitoringv1alpha2.ReasonMissingType, status.Problems[0].Reason)
+	req.Equal(monitoringv1alpha2.ReasonEmptyExpression, status.Problems[1].Reason)
+	req.Equal(int64(1), status.Problems[1].RefID)
+
+	valid := meta.FindStatusCondition(status.Conditions, monitoringv1alpha2.DashboardValid)
+	req.Equal(metav1.ConditionFalse, valid.Status)
+	req.Contains(valid.Message, `panel "Untyped"`)
+
+	queries := meta.FindStatusCondition(status.Conditions, monitoringv1alpha2.DashboardQueriesHealthy)
+	req.Equal(metav1.ConditionFalse, queries.Status)
+	req.Contains(queries.Message, `panel "QPS" refId 1`)
+}
+
+func TestObserveKeepsTransitionTime(t *testing.T) {
+	req := require.New(t)
+
+	spec := &monitoringv1alpha2.DashboardSpec{}
+	first := observeDashboard(spec, 1, monitoringv1alpha2.DashboardStatus{})
+	second := observeDashboard(spec, 2, first)
+
+	for _, condition := range first.Conditions {
+		next := meta.FindStatusCondition(second.Conditions, condition.Type)
+		req.NotNil(next)
+		req.Equal(condition.LastTransitionTime, next.LastTransitionTime)
+		req.Equal(int64(2), next.ObservedGeneration)
+	}
+}
+
+func TestRecordProblemsOnlyOnce(t *testing.T) {
+	req := require.New(t)
+
+	recorder := record.NewFakeRecorder(10)
+	dashboard := &monitoringv1alpha2.Dashboard{}
+	broken := monitoringv1alpha2.DashboardStatus{Problems: []monitoringv1alpha2.PanelProblem{{
+		PanelID:    1,
+		PanelTitle: "QPS",
+		Reason:     monitoringv1alpha2.ReasonUnknownType,
+		Message:    `unknown panel type "grpah"`,
+	}}}
+
+	recordProblems(recorder, dashboard, monitoringv1alpha2.DashboardStatus{}, broken)
+	recordProblems(recorder, dashboard, broken, broken)
+	recordProblems(recorder, dashboard, broken, monitoringv1alpha2.DashboardStatus{})
+
+	req.Len(recorder.Events, 2)
+	req.Equal(`Warning UnknownType panel "QPS": unknown panel type "grpah"`, <-recorder.Events)
+	req.Equal("Normal SpecValid all problems of the dashboard are resolved", <-recorder.Events)
+}
@@ -5,18 +5,31 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	yamlConverter "github.com/ghodss/yaml"
 	"github.com/grafana-tools/sdk"
 	"github.com/mitchellh/mapstructure"
+	"github.com/prometheus/common/model"
 	v1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
 	ansModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/annotations"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
 	panelsModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
 	templatingsModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
 )
 
+// the legacy layout measured heights in pixels, which grafana maps to
+// grid rows of 30px plus an 8px margin
+const (
+	legacyCellHeight    = 38
+	legacyMinHeight     = 90
+	legacyDefaultHeight = 250
+)
+
 type k8sDashboard struct {
 	APIVersion string                  `json:"apiVersion" yaml:"apiVersion"`
 	Kind       string                  `json:"kind" yaml:"kind"`
@@ -28,6 +41,11 @@ type k8sDashboard struct {
 type Converter struct {
 	OutputJson []byte
 	OutputYaml []byte
+
+	// the panels being converted as read from the json, by panel ID
+	rawPanels map[uint]rawPanel
+	// the variables being converted as read from the json, by name
+	rawVariables map[string]rawVariable
 }
 
 // NewConverter: new a Converter struct object with a logger object
@@ -130,13 +148,30 @@ func (converter *Converter) ConvertToKubsphereDashboardManifests(input io.Reader
 // convert reads a input Converter file, then extract needed fields to the yaml model
 func (converter *Converter) convert(content []byte, isClusterCrd bool) (*v1alpha2.DashboardSpec, error) {
 
+	legacy, err := legacyDatasources(content)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall dashboard: %s", err.Error())
+	}
 	board := &sdk.Board{}
-	if err := json.Unmarshal(content, board); err != nil {
+	if err := json.Unmarshal(legacy, board); err != nil {
 		return nil, fmt.Errorf("could not unmarshall dashboard: %s", err.Error())
 	}
 
+	rawPanels, err := readRawPanels(content)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall panels: %s", err.Error())
+	}
+	converter.rawPanels = rawPanels
+	inputs, rawVariables, err := readRawBoard(content)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall dashboard: %s", err.Error())
+	}
+	converter.rawVariables = rawVariables
+
 	// a yaml model
 	dashboard := &v1alpha2.DashboardSpec{}
+	// the datasources the dashboard was exported without, chosen when applying it
+	dashboard.Inputs = inputs
 
 	// starts to convert general settings
 	converter.convertGeneralSettings(board, dashboard)
@@ -162,6 +197,7 @@ func (converter *Converter) convertGeneralSettings(board *sdk.Board, dashboard *
 	dashboard.Time.From = board.Time.From
 	dashboard.Time.To = board.Time.To
 	dashboard.Timezone = board.Timezone
+	dashboard.Links = convertLinks(board.Links)
 
 	if board.Refresh != nil {
 		dashboard.AutoRefresh = board.Refresh.Value
@@ -211,86 +247,259 @@ func (converter *Converter) convertAnnotations(annotations []sdk.Annotation, das
 // convert templating variables
 func (converter *Converter) convertVariables(variables []sdk.TemplateVar, dashboard *v1alpha2.DashboardSpec) {
 	for _, variable := range variables {
-		if variable.Query == nil {
-			continue
-		}
-		q, ok := variable.Query.(string)
-		if !ok {
-			continue
+		v := templatingsModel.TemplateVar{
+			CommonVariable: templatingsModel.CommonVariable{
+				Name:  variable.Name,
+				Type:  variable.Type,
+				Label: variable.Label,
+				Hide:  variable.Hide,
+			},
 		}
-		var options []templatingsModel.Option
-		for _, op := range variable.Options {
-			options = append(options, templatingsModel.Option{
-				Text:     op.Text,
-				Value:    op.Value,
-				Selected: op.Selected,
-			})
+		multi := templatingsModel.MultiValue{
+			Multi:      variable.Multi,
+			IncludeAll: variable.IncludeAll,
+			AllValue:   variable.AllValue,
 		}
-		v := templatingsModel.TemplateVar{
-			Name:        variable.Name,
-			Type:        variable.Type,
-			Auto:        variable.Auto,
-			AutoCount:   variable.AutoCount,
-			Datasource:  variable.Datasource,
-			Options:     options,
-			Query:       q,
-			IncludeAll:  variable.IncludeAll,
-			AllFormat:   variable.AllFormat,
-			AllValue:    variable.AllValue,
-			Multi:       variable.Multi,
-			MultiFormat: variable.MultiFormat,
-			Regex:       variable.Regex,
-			Label:       variable.Label,
-			Hide:        variable.Hide,
-			Sort:        variable.Sort,
+		query := variableQuery(variable.Query)
+
+		switch variable.Type {
+		case templatingsModel.TypeQuery:
+			v.Datasource = converter.variableDatasource(variable)
+			v.QueryVariable = &templatingsModel.QueryVariable{
+				Query:       query,
+				Regex:       variable.Regex,
+				Sort:        variable.Sort,
+				AllFormat:   variable.AllFormat,
+				MultiFormat: variable.MultiFormat,
+				MultiValue:  multi,
+			}
+		case templatingsModel.TypeCustom:
+			v.CustomVariable = &templatingsModel.CustomVariable{MultiValue: multi}
+			for _, op := range variable.Options {
+				v.CustomVariable.Options = append(v.CustomVariable.Options, templatingsModel.Option{
+					Text:     op.Text,
+					Value:    op.Value,
+					Selected: op.Selected,
+				})
+			}
+			if len(v.CustomVariable.Options) == 0 {
+				for _, value := range templatingsModel.SplitValues(query) {
+					v.CustomVariable.Options = append(v.CustomVariable.Options, templatingsModel.Option{Text: value, Value: value})
+				}
+			}
+		case templatingsModel.TypeInterval:
+			v.IntervalVariable = &templatingsModel.IntervalVariable{
+				Intervals: templatingsModel.SplitValues(query),
+				Auto:      variable.Auto,
+				AutoCount: variable.AutoCount,
+			}
+		case templatingsModel.TypeConstant:
+			v.ConstantVariable = &templatingsModel.ConstantVariable{Value: query}
+		case templatingsModel.TypeDatasource:
+			v.DatasourceVariable = &templatingsModel.DatasourceVariable{
+				PluginID:   query,
+				Regex:      variable.Regex,
+				MultiValue: multi,
+			}
+		case templatingsModel.TypeTextbox:
+			v.TextboxVariable = &templatingsModel.TextboxVariable{Default: query}
+		case templatingsModel.TypeAdhoc:
+			v.Datasource = converter.variableDatasource(variable)
+			v.AdhocVariable = &templatingsModel.AdhocVariable{}
+		default:
+			continue
 		}
+
 		dashboard.Templatings = append(dashboard.Templatings, v)
 	}
 }
 
-//convert rows
+// variableDatasource is the datasource of the variable, which the sdk only reads as a name
+func (converter *Converter) variableDatasource(variable sdk.TemplateVar) *datasources.DatasourceRef {
+	if raw := converter.rawVariables[variable.Name]; raw.Datasource != nil {
+		return raw.Datasource
+	}
+	if variable.Datasource == nil {
+		return nil
+	}
+	return datasources.FromName(*variable.Datasource)
+}
+
+// variableQuery reads the query of a variable, which grafana 8 writes as an object for some datasources
+func variableQuery(query interface{}) string {
+	switch q := query.(type) {
+	case string:
+		return q
+	case map[string]interface{}:
+		if s, ok := q["query"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// convert panels
+// a row groups the panels that follow it up to the next row, or holds them itself when collapsed
 func (converter *Converter) convertPanels(panels []*sdk.Panel, dashboard *v1alpha2.DashboardSpec, isClusterCrd bool) {
 
+	var row *panelsModel.Panel
+	var repeatedRow bool
 	for _, panel := range panels {
+		if panel == nil {
+			continue
+		}
 		if panel.Type == "row" {
-			for _, rowPanel := range panel.Panels {
-				convertedPanel, ok := converter.convertDataPanel(rowPanel, isClusterCrd)
-				if ok {
-					dashboard.Panels = append(dashboard.Panels, convertedPanel)
-				}
+			// the copies of repeated rows and panels grafana may save are left out, they are made again when shown
+			repeatedRow = panel.RepeatPanelID != nil
+			if repeatedRow {
+				continue
 			}
+			row = converter.convertRowPanel(*panel, isClusterCrd)
+			dashboard.Panels = append(dashboard.Panels, row)
+			continue
+		}
+		if repeatedRow || panel.RepeatPanelID != nil {
+			continue
+		}
+
+		convertedPanel, ok := converter.convertDataPanel(*panel, isClusterCrd)
+		if !ok {
+			continue
+		}
+		if row != nil {
+			row.RowPanel.Panels = append(row.RowPanel.Panels, convertedPanel)
 		} else {
-			convertedPanel, ok := converter.convertDataPanel(*panel, isClusterCrd)
-			if ok {
-				dashboard.Panels = append(dashboard.Panels, convertedPanel)
-			}
+			dashboard.Panels = append(dashboard.Panels, convertedPanel)
 		}
 	}
 
 }
 
-//convert rows
+// convert rows
+// rows of the legacy dashboard schema become row panels, laid out the way grafana migrates them
 func (converter *Converter) convertRows(rows []*sdk.Row, dashboard *v1alpha2.DashboardSpec, isClusterCrd bool) {
 
+	var y int32
 	for _, row := range rows {
 		if row == nil {
 			continue
 		}
 		panels := row.Panels
-		if panels == nil || len(rows) == 0 {
+		if len(panels) == 0 {
 			continue
 		}
+
+		rowPanel := &panelsModel.Panel{
+			CommonPanel: panelsModel.CommonPanel{
+				Title:   row.Title,
+				Type:    "row",
+				GridPos: &panelsModel.GridPos{Y: y, W: panelsModel.GridColumns, H: 1},
+			},
+			RowPanel: &panelsModel.RowPanel{
+				Collapsed: row.Collapse,
+			},
+		}
+		if row.Repeat != nil {
+			rowPanel.Repeat = *row.Repeat
+		}
+		y++
+
+		rowHeight := legacyGridHeight(string(row.Height))
+		var x, lineHeight int32
 		for _, pl := range panels {
 			convertedPanel, ok := converter.convertDataPanel(pl, isClusterCrd)
-			if ok {
-				dashboard.Panels = append(dashboard.Panels, convertedPanel)
+			if !ok {
+				continue
+			}
+
+			w := legacyGridWidth(pl.Span)
+			h := rowHeight
+			if pl.Height != nil {
+				h = legacyGridHeight(pl.Height)
+			}
+			if x+w > panelsModel.GridColumns {
+				x = 0
+				y += lineHeight
+				lineHeight = 0
+			}
+			convertedPanel.GridPos = &panelsModel.GridPos{X: x, Y: y, W: w, H: h}
+			x += w
+			if h > lineHeight {
+				lineHeight = h
 			}
+
+			rowPanel.RowPanel.Panels = append(rowPanel.RowPanel.Panels, convertedPanel)
+		}
+		y += lineHeight
+
+		dashboard.Panels = append(dashboard.Panels, rowPanel)
+	}
+}
+
+// a row panel, with the panels it holds when collapsed
+func (converter *Converter) convertRowPanel(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
+	row := &panelsModel.Panel{
+		CommonPanel: panelsModel.CommonPanel{
+			Title:   panel.Title,
+			Id:      int64(panel.ID),
+			Type:    panel.Type,
+			GridPos: convertGridPos(panel),
+		},
+		RowPanel: &panelsModel.RowPanel{},
+	}
+	converter.convertRepeat(panel, row)
+
+	if panel.RowPanel == nil {
+		return row
+	}
+	row.RowPanel.Collapsed = panel.RowPanel.Collapsed
+	for _, rowPanel := range panel.RowPanel.Panels {
+		convertedPanel, ok := converter.convertDataPanel(rowPanel, isClusterCrd)
+		if ok {
+			row.RowPanel.Panels = append(row.RowPanel.Panels, convertedPanel)
 		}
 	}
+	return row
+}
+
+// convertRepeat sets the variable the panel is repeated by and how its copies are laid out
+func (converter *Converter) convertRepeat(panel sdk.Panel, converted *panelsModel.Panel) {
+	if panel.Repeat == nil || *panel.Repeat == "" {
+		return
+	}
+	converted.Repeat = *panel.Repeat
+	if converted.Type == "row" {
+		return
+	}
+	raw := converter.rawPanels[panel.ID]
+	converted.RepeatDirection = raw.RepeatDirection
+	converted.MaxPerRow = raw.MaxPerRow
+}
+
+// convertTimeOverride sets the time range of the panel, when it isn't the one of the dashboard
+func (converter *Converter) convertTimeOverride(panel sdk.Panel, converted *panelsModel.Panel) {
+	raw := converter.rawPanels[panel.ID]
+	converted.TimeFrom, converted.TimeShift = raw.TimeFrom, raw.TimeShift
+	converted.HideTimeOverride = raw.HideTimeOverride || boolValue(panel.HideTimeOverride)
+	// the panels of legacy rows aren't read raw, and the sdk only reads the time overrides of graphs
+	if graph := panel.GraphPanel; graph != nil && converted.TimeFrom == "" && converted.TimeShift == "" {
+		converted.TimeFrom, converted.TimeShift = stringValue(graph.TimeFrom), stringValue(graph.TimeShift)
+	}
 }
 
-// convert different types of the given panel
+// convert different types of the given panel, with its links and time and repeat options
 func (converter *Converter) convertDataPanel(panel sdk.Panel, isClusterCrd bool) (*panelsModel.Panel, bool) {
+	converted, ok := converter.convertPanelOfType(panel, isClusterCrd)
+	if ok {
+		numberTargets(converted.Targets)
+		converted.Links = converter.convertPanelLinks(panel)
+		converter.convertRepeat(panel, converted)
+		converter.convertTimeOverride(panel, converted)
+	}
+	return converted, ok
+}
+
+func (converter *Converter) convertPanelOfType(panel sdk.Panel, isClusterCrd bool) (*panelsModel.Panel, bool) {
 	switch panel.Type {
 	case "graph":
 		return converter.convertGraph(panel, isClusterCrd), true
@@ -302,6 +511,14 @@ func (converter *Converter) convertDataPanel(panel sdk.Panel, isClusterCrd bool)
 		return converter.convertTable(panel, isClusterCrd), true
 	case "text":
 		return converter.convertText(panel), true
+	case "timeseries":
+		return converter.convertTimeSeries(panel, isClusterCrd), true
+	case "stat":
+		return converter.convertStat(panel, isClusterCrd), true
+	case "gauge":
+		return converter.convertGauge(panel, isClusterCrd), true
+	case "heatmap":
+		return converter.convertHeatmap(panel, isClusterCrd), true
 	default:
 		if panel.OfType == sdk.CustomType {
 			return converter.convertCustom(panel, isClusterCrd), true
@@ -312,20 +529,14 @@ func (converter *Converter) convertDataPanel(panel sdk.Panel, isClusterCrd bool)
 
 // a graph panel
 func (converter *Converter) convertGraph(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
-	// filled with values of the given fields
-	var height *string
-	if panel.Height != nil {
-		var h = panel.Height.(string)
-		height = &h
-	}
 	graph := &panelsModel.Panel{
 		CommonPanel: panelsModel.CommonPanel{
 			Title:       panel.Title,
 			Id:          int64(panel.ID),
 			Type:        panel.Type,
 			Description: panel.CommonPanel.Description,
-			Height:      height,
-			Datasource:  panel.Datasource,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
 			Colors:      defaultColors(),
 		},
 	}
@@ -338,9 +549,19 @@ func (converter *Converter) convertGraph(panel sdk.Panel, isClusterCrd bool) *pa
 	graph.CommonPanel.Legend = converter.convertLegend(panel.GraphPanel.Legend)
 
 	graph.GraphPanel = &panelsModel.GraphPanel{
-		Bars:  panel.GraphPanel.Bars,
-		Lines: panel.GraphPanel.Lines,
-		Stack: panel.GraphPanel.Stack,
+		Bars:            panel.GraphPanel.Bars,
+		Lines:           panel.GraphPanel.Lines,
+		Stack:           panel.GraphPanel.Stack,
+		Points:          panel.GraphPanel.Points,
+		Fill:            int32(panel.GraphPanel.Fill),
+		LineWidth:       int32(panel.GraphPanel.Linewidth),
+		PointRadius:     floatString(panel.GraphPanel.Pointradius),
+		NullPointMode:   nullPointMode(panel.GraphPanel.NullPointMode),
+		Percentage:      panel.GraphPanel.Percentage,
+		SteppedLine:     panel.GraphPanel.SteppedLine,
+		Tooltip:         convertTooltip(panel.GraphPanel.Tooltip),
+		Thresholds:      convertGraphThresholds(panel.GraphPanel.Thresholds),
+		SeriesOverrides: convertSeriesOverrides(panel.GraphPanel.SeriesOverrides),
 		Xaxis: panelsModel.Axis{
 			Format:   panel.GraphPanel.Xaxis.Format,
 			Decimals: int64(panel.GraphPanel.Xaxis.Decimals),
@@ -349,8 +570,8 @@ func (converter *Converter) convertGraph(panel sdk.Panel, isClusterCrd bool) *pa
 
 	// converts target
 	if panel.GraphPanel.Targets != nil && len(panel.GraphPanel.Targets) > 0 {
-		for index, target := range panel.GraphPanel.Targets {
-			graphTarget := converter.convertTarget(target, index)
+		for _, target := range panel.GraphPanel.Targets {
+			graphTarget := converter.convertTarget(panel, target)
 			if graphTarget == nil {
 				continue
 			}
@@ -360,70 +581,190 @@ func (converter *Converter) convertGraph(panel sdk.Panel, isClusterCrd bool) *pa
 
 	}
 
-	// converts yaxes
+	// converts yaxes, the left one then the right one
 	for _, yaxis := range panel.GraphPanel.Yaxes {
-		graph.GraphPanel.Yaxes = append(graph.GraphPanel.Yaxes, panelsModel.Axis{
-			Format:   handleGraphFormat(yaxis.Format),
-			Decimals: int64(yaxis.Decimals),
-		})
-		break
+		graph.GraphPanel.Yaxes = append(graph.GraphPanel.Yaxes, convertYaxis(yaxis))
 	}
 
 	return graph
 }
 
-func (converter *Converter) convertLegend(sdkLegend sdk.Legend) []string {
-	var legend []string
+// the parts of a timeseries panel read by the converter, which the sdk only exposes as a custom panel
+type timeSeriesPanel struct {
+	FieldConfig struct {
+		Defaults struct {
+			Unit     string
+			Decimals *int64
+			Custom   struct {
+				DrawStyle         string
+				LineInterpolation string
+				FillOpacity       float64
+				AxisPlacement     string
+				Stacking          struct {
+					Mode string
+				}
+			}
+		}
+	}
+	Options struct {
+		Legend struct {
+			DisplayMode string
+			Placement   string
+			Calcs       []string
+			SortBy      string
+			SortDesc    bool
+		}
+	}
+}
 
-	if !sdkLegend.Show {
-		legend = append(legend, "hide")
+// a timeseries panel
+func (converter *Converter) convertTimeSeries(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
+	timeseries := &panelsModel.Panel{
+		CommonPanel: panelsModel.CommonPanel{
+			Title:       panel.Title,
+			Id:          int64(panel.ID),
+			Type:        panel.Type,
+			Description: panel.CommonPanel.Description,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
+			Colors:      defaultColors(),
+		},
 	}
-	if sdkLegend.AlignAsTable {
-		legend = append(legend, "as_table")
+
+	if panel.CustomPanel == nil {
+		return timeseries
+	}
+
+	custom := *panel.CustomPanel
+
+	var sdkTargets []sdk.Target
+	if err := mapstructure.Decode(custom["targets"], &sdkTargets); err == nil {
+		for _, target := range sdkTargets {
+			t := converter.convertTarget(panel, target)
+			if t == nil {
+				continue
+			}
+			timeseries.CommonPanel.Targets = append(timeseries.CommonPanel.Targets, *t)
+		}
+	}
+
+	var options timeSeriesPanel
+	if err := mapstructure.Decode(map[string]interface{}{
+		"fieldConfig": custom["fieldConfig"],
+		"options":     custom["options"],
+	}, &options); err != nil {
+		return timeseries
+	}
+
+	defaults := options.FieldConfig.Defaults
+	timeseries.CommonPanel.Format = defaults.Unit
+	timeseries.CommonPanel.Decimals = defaults.Decimals
+	timeseries.TimeSeriesPanel = &panelsModel.TimeSeriesPanel{
+		DrawStyle:         defaults.Custom.DrawStyle,
+		LineInterpolation: defaults.Custom.LineInterpolation,
+		FillOpacity:       int32(defaults.Custom.FillOpacity),
+		StackingMode:      defaults.Custom.Stacking.Mode,
+		AxisPlacement:     defaults.Custom.AxisPlacement,
+	}
+	legend := options.Options.Legend
+	timeseries.CommonPanel.Legend = convertLegendOptions(legend.DisplayMode, legend.Placement, legend.Calcs, legend.SortBy, legend.SortDesc)
+	timeseries.CommonPanel.FieldConfig = converter.convertFieldConfig(panel)
+
+	return timeseries
+}
+
+// converts the legend of a graph panel
+func (converter *Converter) convertLegend(sdkLegend sdk.Legend) *panelsModel.Legend {
+	legend := &panelsModel.Legend{
+		DisplayMode: panelsModel.LegendDisplayList,
+		Placement:   panelsModel.LegendPlacementBottom,
+		HideEmpty:   sdkLegend.HideEmpty,
+		HideZero:    sdkLegend.HideZero,
+	}
+
+	if !sdkLegend.Show {
+		legend.DisplayMode = panelsModel.LegendDisplayHidden
+	} else if sdkLegend.AlignAsTable {
+		legend.DisplayMode = panelsModel.LegendDisplayTable
 	}
 	if sdkLegend.RightSide {
-		legend = append(legend, "to_the_right")
+		legend.Placement = panelsModel.LegendPlacementRight
 	}
 	if sdkLegend.Min {
-		legend = append(legend, "min")
+		legend.Calcs = append(legend.Calcs, panelsModel.CalcMin)
 	}
 	if sdkLegend.Max {
-		legend = append(legend, "max")
+		legend.Calcs = append(legend.Calcs, panelsModel.CalcMax)
 	}
 	if sdkLegend.Avg {
-		legend = append(legend, "avg")
+		legend.Calcs = append(legend.Calcs, panelsModel.CalcMean)
 	}
 	if sdkLegend.Current {
-		legend = append(legend, "current")
+		legend.Calcs = append(legend.Calcs, panelsModel.CalcLastNotNull)
 	}
 	if sdkLegend.Total {
-		legend = append(legend, "total")
+		legend.Calcs = append(legend.Calcs, panelsModel.CalcSum)
 	}
-	if sdkLegend.HideEmpty {
-		legend = append(legend, "no_null_series")
-	}
-	if sdkLegend.HideZero {
-		legend = append(legend, "no_zero_series")
+
+	return legend
+}
+
+// converts the legend options of the panels introduced with grafana 7.
+// The series are sorted by the display name of a calc, such as "Mean".
+func convertLegendOptions(displayMode, placement string, calcs []string, sortBy string, sortDesc bool) *panelsModel.Legend {
+	if displayMode == "" && placement == "" && len(calcs) == 0 {
+		return nil
 	}
 
+	legend := &panelsModel.Legend{
+		DisplayMode: displayMode,
+		Placement:   placement,
+	}
+	for _, calc := range calcs {
+		if !isKnownCalc(calc) {
+			continue
+		}
+		legend.Calcs = append(legend.Calcs, panelsModel.Calc(calc))
+		if strings.EqualFold(calc, sortBy) {
+			legend.SortBy = panelsModel.Calc(calc)
+			legend.SortDesc = sortDesc
+		}
+	}
 	return legend
 }
 
+// calculations of grafana understood by the dashboard
+var supportedCalcs = map[string]bool{
+	"min": true, "max": true, "mean": true, "sum": true, "count": true,
+	"first": true, "firstNotNull": true, "last": true, "lastNotNull": true,
+	"range": true, "delta": true, "diff": true, "stdDev": true, "variance": true,
+}
+
+func isKnownCalc(calc string) bool {
+	return supportedCalcs[calc]
+}
+
+// knownCalcs leaves out the calculations the dashboard does not understand
+func knownCalcs(in []panelsModel.Calc) []panelsModel.Calc {
+	var out []panelsModel.Calc
+	for _, calc := range in {
+		if isKnownCalc(string(calc)) {
+			out = append(out, calc)
+		}
+	}
+	return out
+}
+
 // singlestat panel
 func (converter *Converter) convertSingleStat(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
-	var height *string
-	if panel.Height != nil {
-		var h = panel.Height.(string)
-		height = &h
-	}
 	singleStat := &panelsModel.Panel{
 		CommonPanel: panelsModel.CommonPanel{
 			Title:       panel.Title,
 			Id:          int64(panel.ID),
 			Type:        panel.Type,
 			Description: panel.CommonPanel.Description,
-			Height:      height,
-			Datasource:  panel.Datasource,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
 		},
 	}
 
@@ -437,6 +778,12 @@ func (converter *Converter) convertSingleStat(panel sdk.Panel, isClusterCrd bool
 	singleStat.SinglestatPanel = &panelsModel.SinglestatPanel{
 		ValueName: panel.SinglestatPanel.ValueName,
 	}
+	singleStat.CommonPanel.FieldConfig = convertLegacyFieldConfig(
+		panel.SinglestatPanel.Thresholds,
+		panel.SinglestatPanel.Colors,
+		panel.SinglestatPanel.ValueMaps,
+		panel.SinglestatPanel.RangeMaps,
+	)
 
 	if len(panel.SinglestatPanel.Colors) == 3 {
 		singleStat.CommonPanel.Colors = []string{
@@ -457,8 +804,8 @@ func (converter *Converter) convertSingleStat(panel sdk.Panel, isClusterCrd bool
 
 	// handles targets
 	if panel.SinglestatPanel.Targets != nil && len(panel.SinglestatPanel.Targets) > 0 {
-		for index, target := range panel.SinglestatPanel.Targets {
-			target := converter.convertTarget(target, index)
+		for _, target := range panel.SinglestatPanel.Targets {
+			target := converter.convertTarget(panel, target)
 			if target == nil {
 				continue
 			}
@@ -480,22 +827,252 @@ func (converter *Converter) convertSingleStat(panel sdk.Panel, isClusterCrd bool
 	return singleStat
 }
 
-// gauge
+// the field options of a stat panel read by the converter
+type statDefaults struct {
+	Unit     string
+	Decimals *int64
+}
+
+// a stat panel
+func (converter *Converter) convertStat(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
+	stat := &panelsModel.Panel{
+		CommonPanel: panelsModel.CommonPanel{
+			Title:       panel.Title,
+			Id:          int64(panel.ID),
+			Type:        panel.Type,
+			Description: panel.CommonPanel.Description,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
+		},
+	}
+
+	if panel.StatPanel == nil {
+		return stat
+	}
+
+	// the unit and decimals of grafana 7 and later stat panels are field options
+	var defaults statDefaults
+	if err := mapstructure.Decode(converter.rawPanels[panel.ID].FieldConfig["defaults"], &defaults); err == nil {
+		stat.CommonPanel.Format = defaults.Unit
+		stat.CommonPanel.Decimals = defaults.Decimals
+	}
+
+	options := panel.StatPanel.Options
+	stat.CommonPanel.ReduceOptions = convertReduceOptions(options.ReduceOptions.Calcs, options.ReduceOptions.Fields, options.ReduceOptions.Values)
+	stat.CommonPanel.FieldConfig = converter.convertFieldConfig(panel)
+	stat.StatPanel = &panelsModel.StatPanel{
+		Orientation: options.Orientation,
+		TextMode:    options.TextMode,
+		ColorMode:   options.ColorMode,
+		GraphMode:   options.GraphMode,
+	}
+
+	for _, target := range panel.StatPanel.Targets {
+		statTarget := converter.convertTarget(panel, target)
+		if statTarget == nil {
+			continue
+		}
+		stat.CommonPanel.Targets = append(stat.CommonPanel.Targets, *statTarget)
+	}
+
+	return stat
+}
+
+// the parts of a gauge panel read by the converter, which the sdk only exposes as a custom panel
+type gaugePanel struct {
+	FieldConfig struct {
+		Defaults struct {
+			Unit     string
+			Decimals *int64
+		}
+	}
+	Options struct {
+		Orientation          string
+		ShowThresholdLabels  bool
+		ShowThresholdMarkers bool
+		ReduceOptions        struct {
+			Calcs  []string
+			Fields string
+			Values bool
+		}
+	}
+}
+
+// a gauge panel
+func (converter *Converter) convertGauge(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
+	gauge := &panelsModel.Panel{
+		CommonPanel: panelsModel.CommonPanel{
+			Title:       panel.Title,
+			Id:          int64(panel.ID),
+			Type:        panel.Type,
+			Description: panel.CommonPanel.Description,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
+		},
+	}
+
+	if panel.CustomPanel == nil {
+		return gauge
+	}
+
+	custom := *panel.CustomPanel
+
+	var sdkTargets []sdk.Target
+	if err := mapstructure.Decode(custom["targets"], &sdkTargets); err == nil {
+		for _, target := range sdkTargets {
+			t := converter.convertTarget(panel, target)
+			if t == nil {
+				continue
+			}
+			gauge.CommonPanel.Targets = append(gauge.CommonPanel.Targets, *t)
+		}
+	}
+
+	var options gaugePanel
+	if err := mapstructure.Decode(map[string]interface{}{
+		"fieldConfig": custom["fieldConfig"],
+		"options":     custom["options"],
+	}, &options); err != nil {
+		return gauge
+	}
+
+	reduce := options.Options.ReduceOptions
+	gauge.CommonPanel.Format = options.FieldConfig.Defaults.Unit
+	gauge.CommonPanel.Decimals = options.FieldConfig.Defaults.Decimals
+	gauge.CommonPanel.ReduceOptions = convertReduceOptions(reduce.Calcs, reduce.Fields, reduce.Values)
+	gauge.CommonPanel.FieldConfig = converter.convertFieldConfig(panel)
+	gauge.GaugePanel = &panelsModel.GaugePanel{
+		Orientation:          options.Options.Orientation,
+		ShowThresholdLabels:  options.Options.ShowThresholdLabels,
+		ShowThresholdMarkers: options.Options.ShowThresholdMarkers,
+	}
+
+	return gauge
+}
+
+// converts a heatmap panel
+func (converter *Converter) convertHeatmap(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
+	heatmap := &panelsModel.Panel{
+		CommonPanel: panelsModel.CommonPanel{
+			Title:       panel.Title,
+			Id:          int64(panel.ID),
+			Type:        panel.Type,
+			Description: panel.CommonPanel.Description,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
+		},
+	}
+
+	if panel.HeatmapPanel == nil {
+		return heatmap
+	}
+
+	for _, target := range panel.HeatmapPanel.Targets {
+		t := converter.convertTarget(panel, target)
+		if t == nil {
+			continue
+		}
+		heatmap.CommonPanel.Targets = append(heatmap.CommonPanel.Targets, *t)
+	}
+
+	options := panel.HeatmapPanel
+	heatmap.HeatmapPanel = &panelsModel.HeatmapPanel{
+		HideZeroBuckets:  options.HideZeroBuckets,
+		HighlightCards:   options.HighlightCards,
+		ReverseYBuckets:  options.ReverseYBuckets,
+		YBucketSize:      float64pointString(options.YBucketSize),
+		TooltipHistogram: options.Tooltip.ShowHistogram,
+		YAxis: panelsModel.Axis{
+			Format: options.YAxis.Format,
+			Min:    stringValue(options.YAxis.Min),
+			Max:    stringValue(options.YAxis.Max),
+		},
+	}
+
+	if show := converter.rawPanels[panel.ID].Tooltip.Show; show != nil {
+		heatmap.HeatmapPanel.HideTooltip = !*show
+	}
+
+	switch options.DataFormat {
+	case panelsModel.HeatmapDataFormatTimeSeries, panelsModel.HeatmapDataFormatTSBuckets:
+		heatmap.HeatmapPanel.DataFormat = options.DataFormat
+	}
+	switch options.YBucketBound {
+	case "auto", "upper", "lower", "middle":
+		heatmap.HeatmapPanel.YBucketBound = options.YBucketBound
+	}
+	if options.YBucketNumber != nil && *options.YBucketNumber >= 1 {
+		heatmap.HeatmapPanel.YBucketNumber = int32(*options.YBucketNumber)
+	}
+
+	yaxis := &heatmap.HeatmapPanel.YAxis
+	if options.YAxis.Decimals != nil {
+		yaxis.Decimals = int64(*options.YAxis.Decimals)
+	}
+	if options.YAxis.LogBase > 1 {
+		yaxis.LogBase = int32(options.YAxis.LogBase)
+	}
+	if !options.YAxis.Show {
+		yaxis.Show = &options.YAxis.Show
+	}
+
+	color := panelsModel.HeatmapColor{
+		ColorScheme: options.Color.ColorScheme,
+		CardColor:   options.Color.CardColor,
+		Min:         float64pointString(options.Color.Min),
+		Max:         float64pointString(options.Color.Max),
+	}
+	switch options.Color.Mode {
+	case panelsModel.HeatmapColorModeSpectrum, panelsModel.HeatmapColorModeOpacity:
+		color.Mode = options.Color.Mode
+	}
+	switch options.Color.ColorScale {
+	case "linear", "sqrt":
+		color.ColorScale = options.Color.ColorScale
+	}
+	if options.Color.Exponent != 0 {
+		color.Exponent = strconv.FormatFloat(options.Color.Exponent, 'f', -1, 64)
+	}
+	if color != (panelsModel.HeatmapColor{}) {
+		heatmap.HeatmapPanel.Color = &color
+	}
+
+	return heatmap
+}
+
+// convertReduceOptions leaves out empty reduce options
+func convertReduceOptions(calcs []string, fields string, values bool) *panelsModel.ReduceOptions {
+	if len(calcs) == 0 && fields == "" && !values {
+		return nil
+	}
+	return &panelsModel.ReduceOptions{
+		Calcs:  calcs,
+		Fields: fields,
+		Values: values,
+	}
+}
+
+// the panel fields grafana writes for every panel type, the other fields of a plugin panel are its options
+var grafanaPanelFields = map[string]bool{
+	"id": true, "type": true, "title": true, "description": true, "datasource": true,
+	"gridPos": true, "span": true, "height": true, "transparent": true, "pluginVersion": true,
+	"targets": true, "fieldConfig": true, "transformations": true, "links": true,
+	"repeat": true, "repeatDirection": true, "maxPerRow": true, "scopedVars": true,
+	"timeFrom": true, "timeShift": true, "hideTimeOverride": true,
+	"interval": true, "maxDataPoints": true, "cacheTimeout": true,
+}
+
+// a panel of a plugin type, which keeps its type and options so that an extension can read them
 func (converter *Converter) convertCustom(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
 	// set options
-	var height *string
-	if panel.Height != nil {
-		var h = panel.Height.(string)
-		height = &h
-	}
 	customPanel := &panelsModel.Panel{
 		CommonPanel: panelsModel.CommonPanel{
 			Title:       panel.Title,
 			Id:          int64(panel.ID),
-			Type:        "singlestat",
+			Type:        panel.Type,
 			Description: panel.CommonPanel.Description,
-			Height:      height,
-			Datasource:  panel.Datasource,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
 		},
 	}
 
@@ -513,8 +1090,8 @@ func (converter *Converter) convertCustom(panel sdk.Panel, isClusterCrd bool) *p
 
 	var targets []panelsModel.Target
 
-	for index, target := range sdkTargets {
-		t := converter.convertTarget(target, index)
+	for _, target := range sdkTargets {
+		t := converter.convertTarget(panel, target)
 		if t == nil {
 			continue
 		}
@@ -522,26 +1099,43 @@ func (converter *Converter) convertCustom(panel sdk.Panel, isClusterCrd bool) *p
 	}
 
 	customPanel.CommonPanel.Targets = targets
+	customPanel.CommonPanel.FieldConfig = converter.convertFieldConfig(panel)
+	customPanel.Custom = customOptions(custom)
 
 	return customPanel
 }
 
+// customOptions returns the options of a plugin panel as kept by Panel.Custom. Reading them
+// back as a panel leaves out the fields a panel has whatever its type.
+func customOptions(custom sdk.CustomPanel) panelsModel.CustomPanel {
+	options := make(map[string]interface{}, len(custom))
+	for name, value := range custom {
+		if !grafanaPanelFields[name] {
+			options[name] = value
+		}
+	}
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return nil
+	}
+	var panel panelsModel.Panel
+	if err := json.Unmarshal(raw, &panel); err != nil {
+		return nil
+	}
+	return panel.Custom
+}
+
 // bar gauge
 func (converter *Converter) convertBarGauge(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
 	// set options
-	var height *string
-	if panel.Height != nil {
-		var h = panel.Height.(string)
-		height = &h
-	}
 	barGaugePanel := &panelsModel.Panel{
 		CommonPanel: panelsModel.CommonPanel{
 			Title:       panel.Title,
 			Id:          int64(panel.ID),
 			Type:        panel.Type,
 			Description: panel.CommonPanel.Description,
-			Height:      height,
-			Datasource:  panel.Datasource,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
 		},
 	}
 
@@ -561,11 +1155,12 @@ func (converter *Converter) convertBarGauge(panel sdk.Panel, isClusterCrd bool)
 			Mode:        panel.BarGaugePanel.Options.Mode,
 		},
 	}
+	barGaugePanel.CommonPanel.FieldConfig = converter.convertFieldConfig(panel)
 
 	// handles targets
 	if panel.BarGaugePanel.Targets != nil && len(panel.BarGaugePanel.Targets) > 0 {
-		for index, target := range panel.BarGaugePanel.Targets {
-			barGaugeTarget := converter.convertTarget(target, index)
+		for _, target := range panel.BarGaugePanel.Targets {
+			barGaugeTarget := converter.convertTarget(panel, target)
 			if barGaugeTarget == nil {
 				continue
 			}
@@ -580,19 +1175,14 @@ func (converter *Converter) convertBarGauge(panel sdk.Panel, isClusterCrd bool)
 
 // converts a table panel
 func (converter *Converter) convertTable(panel sdk.Panel, isClusterCrd bool) *panelsModel.Panel {
-	var height *string
-	if panel.Height != nil {
-		var h = panel.Height.(string)
-		height = &h
-	}
 	tablePanel := &panelsModel.Panel{
 		CommonPanel: panelsModel.CommonPanel{
 			Title:       panel.Title,
 			Id:          int64(panel.ID),
 			Type:        panel.Type,
 			Description: panel.CommonPanel.Description,
-			Height:      height,
-			Datasource:  panel.Datasource,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
 		},
 	}
 
@@ -601,12 +1191,14 @@ func (converter *Converter) convertTable(panel sdk.Panel, isClusterCrd bool) *pa
 	}
 
 	tablePanel.TablePanel = &panelsModel.TablePanel{
-		Scroll: panel.TablePanel.Scroll,
+		Scroll:          panel.TablePanel.Scroll,
+		Transformations: converter.convertTransformations(panel),
 	}
+	tablePanel.TablePanel.Styles, tablePanel.TablePanel.HiddenColumns = convertColumnStyles(panel.TablePanel.Styles)
 
 	if panel.TablePanel.Targets != nil && len(panel.TablePanel.Targets) > 0 {
-		for index, target := range panel.TablePanel.Targets {
-			graphTarget := converter.convertTarget(target, index)
+		for _, target := range panel.TablePanel.Targets {
+			graphTarget := converter.convertTarget(panel, target)
 			if graphTarget == nil {
 				continue
 			}
@@ -627,11 +1219,6 @@ func (converter *Converter) convertTable(panel sdk.Panel, isClusterCrd bool) *pa
 
 // converts a text panel
 func (converter *Converter) convertText(panel sdk.Panel) *panelsModel.Panel {
-	var height *string
-	if panel.Height != nil {
-		var h = panel.Height.(string)
-		height = &h
-	}
 
 	textPanel := &panelsModel.Panel{
 		CommonPanel: panelsModel.CommonPanel{
@@ -639,8 +1226,8 @@ func (converter *Converter) convertText(panel sdk.Panel) *panelsModel.Panel {
 			Id:          int64(panel.ID),
 			Type:        panel.Type,
 			Description: panel.CommonPanel.Description,
-			Height:      height,
-			Datasource:  panel.Datasource,
+			GridPos:     convertGridPos(panel),
+			Datasource:  converter.panelDatasource(panel),
 		},
 	}
 
@@ -656,16 +1243,34 @@ func (converter *Converter) convertText(panel sdk.Panel) *panelsModel.Panel {
 	return textPanel
 }
 
-func (converter *Converter) convertTarget(target sdk.Target, index int) *panelsModel.Target {
+func (converter *Converter) convertTarget(panel sdk.Panel, target sdk.Target) *panelsModel.Target {
 	// looks like a prometheus target
-	return converter.convertPrometheusTarget(target, index)
+	return converter.convertPrometheusTarget(panel, target)
 }
 
-func (converter *Converter) convertPrometheusTarget(target sdk.Target, index int) *panelsModel.Target {
+func (converter *Converter) convertPrometheusTarget(panel sdk.Panel, target sdk.Target) *panelsModel.Target {
+	// refIds are numbered after their letters, so that A stays 1 whatever the order of the targets,
+	// and kept as is for the expressions and overrides referring to them. The other refIds are
+	// numbered by numberTargets once all the targets of the panel are converted.
+	refID := panelsModel.RefIDFromName(target.RefID)
+	raw := converter.rawTarget(panel.ID, target.RefID)
+
 	t := &panelsModel.Target{
-		// RefID: target.RefID,
-		RefID:        int64(index) + 1,
-		LegendFormat: handleLegendFormat(target.LegendFormat),
+		RefID:          refID,
+		RefName:        target.RefID,
+		LegendFormat:   handleLegendFormat(target.LegendFormat),
+		Instant:        target.Instant,
+		Range:          raw.Range,
+		Format:         convertTargetFormat(target.Format),
+		Interval:       target.Interval,
+		IntervalFactor: target.IntervalFactor,
+		Hide:           target.Hide,
+		Exemplar:       raw.Exemplar,
+		TimeShift:      raw.TimeShift,
+	}
+	t.Datasource = raw.Datasource
+	if t.Datasource == nil {
+		t.Datasource = datasources.FromName(target.Datasource)
 	}
 
 	// adjusts the query expression to adapt to the ks cluster
@@ -676,11 +1281,100 @@ func (converter *Converter) convertPrometheusTarget(target sdk.Target, index int
 	}
 
 	t.Expression = fmt.Sprintf("%s", converedExpr)
-	t.Step = toString(target.Step)
+	t.Step = convertStep(target)
 	return t
 
 }
 
+// numberTargets numbers the targets whose refId isn't made of letters after the highest refId in use
+func numberTargets(targets []panelsModel.Target) {
+	var maxRefID int64
+	for _, target := range targets {
+		if target.RefID > maxRefID {
+			maxRefID = target.RefID
+		}
+	}
+	for i := range targets {
+		if targets[i].RefID == 0 {
+			maxRefID++
+			targets[i].RefID = maxRefID
+		}
+	}
+}
+
+// panelDatasource is the datasource of the panel, which the sdk only reads as a name
+func (converter *Converter) panelDatasource(panel sdk.Panel) *datasources.DatasourceRef {
+	if raw := converter.rawPanels[panel.ID]; raw.Datasource != nil {
+		return raw.Datasource
+	}
+	if panel.Datasource == nil {
+		return nil
+	}
+	return datasources.FromName(*panel.Datasource)
+}
+
+// convertTargetFormat keeps the formats the model knows, the default time series otherwise
+func convertTargetFormat(format string) string {
+	switch format {
+	case panelsModel.TargetFormatTimeSeries, panelsModel.TargetFormatTable, panelsModel.TargetFormatHeatmap:
+		return format
+	}
+	return ""
+}
+
+// convertGridPos reads the position of the panel, when it has one
+func convertGridPos(panel sdk.Panel) *panelsModel.GridPos {
+	pos := panel.GridPos
+	if pos.W == nil && pos.H == nil {
+		return nil
+	}
+
+	value := func(p *int) int32 {
+		if p == nil {
+			return 0
+		}
+		return int32(*p)
+	}
+	return &panelsModel.GridPos{
+		X: value(pos.X),
+		Y: value(pos.Y),
+		W: value(pos.W),
+		H: value(pos.H),
+	}
+}
+
+// legacyGridWidth converts a span of the 12 column legacy layout to grid columns
+func legacyGridWidth(span float32) int32 {
+	if span <= 0 {
+		span = 12
+	}
+	w := int32(span * panelsModel.GridColumns / 12)
+	if w > panelsModel.GridColumns {
+		w = panelsModel.GridColumns
+	}
+	return w
+}
+
+// legacyGridHeight converts a height in pixels, such as 250 or "250px", to grid rows
+func legacyGridHeight(height interface{}) int32 {
+	var px float64
+	switch h := height.(type) {
+	case string:
+		px, _ = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(h), "px"), 64)
+	case float64:
+		px = h
+	case int:
+		px = float64(h)
+	}
+	if px <= 0 {
+		px = legacyDefaultHeight
+	}
+	if px < legacyMinHeight {
+		px = legacyMinHeight
+	}
+	return int32(math.Ceil(px / legacyCellHeight))
+}
+
 func panelSpan(panel sdk.Panel) int64 {
 	return int64(panel.ID)
 }
@@ -698,16 +1392,13 @@ func handleZimu(z string) int64 {
 	return int64(n) + 1
 }
 
-func toString(step int) string {
-	// number := int(step / 60)
-	// if number == 0 {
-	// 	return strconv.Itoa(step) + "s"
-	// }
-	// if number > 60 {
-	// 	return strconv.Itoa(number/60) + "h"
-	// }
-	// return strconv.Itoa(number) + "m"
-	return "1m"
+// convertStep reads the legacy step of a target, in seconds. Targets now set their interval instead,
+// without either the step is computed from $__interval when the dashboard is queried.
+func convertStep(target sdk.Target) string {
+	if target.Step > 0 {
+		return model.Duration(time.Duration(target.Step) * time.Second).String()
+	}
+	return ""
 }
 
 func handleGraphFormat(f string) string {
@@ -750,13 +1441,29 @@ func uintpointToInt64point(up *uint) *int64 {
 	return nil
 }
 
+func intpointToInt32point(ip *int) *int32 {
+	if ip != nil {
+		var t = int32(*ip)
+		return &t
+	}
+	return nil
+}
+
+// float64pointString formats a number, leaving nil out
+func float64pointString(fp *float64) string {
+	if fp == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*fp, 'f', -1, 64)
+}
+
 func intToInt64point(o int) *int64 {
 	var c = int64(o)
 	return &c
 }
 
 func defaultColors() []string {
-	return []string{"#60acfc", "#23c2db", "#64d5b2", "#d5ec5a", "#ffb64e", "#fb816d", "#d15c7f"}
+	return panelsModel.DefaultColors()
 }
 
 func convertExpr(expr string) string {
@@ -766,17 +1473,11 @@ func convertExpr(expr string) string {
 	if !pat.Match([]byte(expr)) {
 		return ""
 	}
-	// handles $interval or $__interval
-	pat1 := regexp.MustCompile(`\$_{0,2}interval`)
-	if pat1.Match([]byte(expr)) {
-		expr = pat1.ReplaceAllString(expr, "3m")
-	}
+	// $interval and the built-in variables such as $__interval and $__range are kept,
+	// they are computed when the dashboard is queried
 
-	// if contains irate/rate/count func, just removes `\{.*\}`
-	pat2 := regexp.MustCompile(`\{.*\}`)
-	if matchCommon := pat2.Match([]byte(expr)); matchCommon {
-		expr = pat2.ReplaceAllString(expr, "")
-	}
+	// if contains irate/rate/count func, just removes the label matchers `\{...\}`
+	expr = removeLabelMatchers(expr)
 
 	// if contains count, removes `>\d+`
 	pat3 := regexp.MustCompile(`>\d+`)
@@ -786,3 +1487,48 @@ func convertExpr(expr string) string {
 	return expr
 
 }
+
+// removeLabelMatchers removes the `{...}` label matchers of the expression. Braced variables such as
+// ${job} or [${__rate_interval}] are kept, and braces in the quoted label values are skipped.
+func removeLabelMatchers(expr string) string {
+	var b strings.Builder
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if depth == 0 {
+			if c == '$' && i+1 < len(expr) && expr[i+1] == '{' {
+				end := strings.IndexByte(expr[i:], '}')
+				if end < 0 {
+					b.WriteString(expr[i:])
+					break
+				}
+				b.WriteString(expr[i : i+end+1])
+				i += end
+				continue
+			}
+			if c == '{' {
+				depth = 1
+				continue
+			}
+			b.WriteByte(c)
+			continue
+		}
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		}
+	}
+	return b.String()
+}
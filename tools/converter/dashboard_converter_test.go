@@ -2,11 +2,17 @@ package converter
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/grafana-tools/sdk"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	v1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+	panelsModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	templatingsModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
 )
 
 func defaultVar(varType string) sdk.TemplateVar {
@@ -85,12 +91,60 @@ func TestConvertQueryVar(t *testing.T) {
 	req.Equal("var_query", query.Name)
 	req.Equal("query", query.Type)
 	req.Equal("Query", query.Label)
-	req.Equal(datasource, *query.Datasource)
-	req.Equal("prom_query", query.Query)
-	req.True(query.IncludeAll)
+	req.Equal(&datasources.DatasourceRef{Name: datasource}, query.Datasource)
+	req.Equal("prom_query", query.QueryVariable.Query)
+	req.True(query.QueryVariable.IncludeAll)
 
 }
 
+func TestConvertVariableKinds(t *testing.T) {
+	req := require.New(t)
+
+	custom := defaultVar("custom")
+	custom.Name = "env"
+	custom.Query = "prod, staging"
+	custom.Multi = true
+
+	interval := defaultVar("interval")
+	interval.Name = "step"
+	interval.Query = "1m,5m,1h"
+	interval.Auto = true
+
+	constant := defaultVar("constant")
+	constant.Name = "cluster"
+	constant.Query = "host"
+
+	datasource := defaultVar("datasource")
+	datasource.Name = "ds"
+	datasource.Query = "prometheus"
+
+	textbox := defaultVar("textbox")
+	textbox.Name = "filter"
+	textbox.Query = ".*"
+
+	query := defaultVar("query")
+	query.Name = "namespace"
+	query.Query = map[string]interface{}{"query": "label_values(namespace)", "refId": "StandardVariableQuery"}
+
+	converter := NewConverter()
+	dashboard := &v1alpha2.DashboardSpec{}
+	converter.convertVariables([]sdk.TemplateVar{custom, interval, constant, datasource, textbox, query, defaultVar("unknown")}, dashboard)
+
+	req.Len(dashboard.Templatings, 6)
+	req.Equal(&templatingsModel.CustomVariable{
+		Options:    []templatingsModel.Option{{Text: "prod", Value: "prod"}, {Text: "staging", Value: "staging"}},
+		MultiValue: templatingsModel.MultiValue{Multi: true},
+	}, dashboard.Templatings[0].CustomVariable)
+	req.Equal(&templatingsModel.IntervalVariable{Intervals: []string{"1m", "5m", "1h"}, Auto: true}, dashboard.Templatings[1].IntervalVariable)
+	req.Equal("host", dashboard.Templatings[2].ConstantVariable.Value)
+	req.Equal("prometheus", dashboard.Templatings[3].DatasourceVariable.PluginID)
+	req.Equal(".*", dashboard.Templatings[4].TextboxVariable.Default)
+	req.Equal("label_values(namespace)", dashboard.Templatings[5].QueryVariable.Query)
+	for _, variable := range dashboard.Templatings {
+		req.Empty(variable.Validate(field.NewPath("variable")), variable.Name)
+	}
+}
+
 func TestConvertTargetWithPrometheusTarget(t *testing.T) {
 	req := require.New(t)
 
@@ -102,12 +156,157 @@ func TestConvertTargetWithPrometheusTarget(t *testing.T) {
 		RefID:        "A",
 	}
 
-	convertedTarget := converter.convertTarget(target, 0)
+	convertedTarget := converter.convertTarget(sdk.Panel{}, target)
 
 	req.NotNil(convertedTarget)
 	req.Equal("prometheus_query", convertedTarget.Expression)
 	req.Equal("{{field}}", convertedTarget.LegendFormat)
 	req.Equal(int64(1), convertedTarget.RefID)
+	req.Equal("A", convertedTarget.RefName)
+}
+
+func TestConvertTargetOptions(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [{
+			"id": 1,
+			"type": "graph",
+			"datasource": "prometheus",
+			"targets": [
+				{"refId": "B", "expr": "up", "instant": true, "format": "table", "hide": true},
+				{"refId": "A", "expr": "rate(http_requests_total{job=\"api\"}[$__rate_interval])", "range": true, "exemplar": true,
+					"interval": "$interval", "intervalFactor": 2, "datasource": "thanos"},
+				{"refId": "cpu", "expr": "node_load1", "format": "time_series"}
+			]
+		}]
+	}`), false)
+	req.NoError(err)
+
+	targets := dashboard.Panels[0].Targets
+	req.Len(targets, 3)
+
+	req.Equal(int64(2), targets[0].RefID)
+	req.Equal("B", targets[0].RefName)
+	req.True(targets[0].Instant)
+	req.False(targets[0].IsRange())
+	req.Equal(panelsModel.TargetFormatTable, targets[0].Format)
+	req.True(targets[0].Hide)
+	req.Nil(targets[0].Datasource)
+
+	req.Equal(int64(1), targets[1].RefID)
+	req.Equal("A", targets[1].RefName)
+	req.True(targets[1].Range)
+	req.True(targets[1].Exemplar)
+	req.Equal("$interval", targets[1].Interval)
+	req.Equal(2, targets[1].IntervalFactor)
+	req.Equal(&datasources.DatasourceRef{Name: "thanos"}, targets[1].Datasource)
+
+	req.Equal(int64(3), targets[2].RefID)
+	req.Equal("cpu", targets[2].RefName)
+	req.True(targets[2].IsRange())
+	req.Equal(panelsModel.TargetFormatTimeSeries, targets[2].Format)
+}
+
+func TestConvertRefIDsWithoutLetters(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [{
+			"id": 1,
+			"type": "graph",
+			"targets": [
+				{"refId": "cpu", "expr": "node_load1"},
+				{"refId": "A", "expr": "up"},
+				{"expr": "node_load5"}
+			]
+		}]
+	}`), false)
+	req.NoError(err)
+
+	targets := dashboard.Panels[0].Targets
+	req.Len(targets, 3)
+	req.Equal(int64(2), targets[0].RefID)
+	req.Equal(int64(1), targets[1].RefID)
+	req.Equal(int64(3), targets[2].RefID)
+	req.Empty(dashboard.Validate())
+}
+
+func TestConvertDatasources(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"__inputs": [
+			{"name": "DS_PROMETHEUS", "label": "Prometheus", "type": "datasource", "pluginId": "prometheus", "pluginName": "Prometheus"},
+			{"name": "VAR_CLUSTER", "type": "constant", "value": "host"}
+		],
+		"templating": {"list": [
+			{"name": "namespace", "type": "query", "query": "label_values(namespace)", "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}}
+		]},
+		"annotations": {"list": [
+			{"name": "Deploys", "type": "tags", "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}}
+		]},
+		"panels": [{
+			"id": 1,
+			"type": "graph",
+			"datasource": {"type": "datasource", "uid": "-- Mixed --"},
+			"targets": [
+				{"refId": "A", "expr": "up", "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}},
+				{"refId": "B", "expr": "up", "datasource": {"type": "prometheus", "uid": "thanos"}}
+			]
+		}, {
+			"id": 2,
+			"type": "graph",
+			"datasource": "${DS_PROMETHEUS}",
+			"targets": [{"refId": "A", "expr": "up"}]
+		}]
+	}`), false)
+	req.NoError(err)
+
+	req.Equal([]datasources.Input{{Name: "DS_PROMETHEUS", Label: "Prometheus", PluginID: "prometheus"}}, dashboard.Inputs)
+	prometheus := &datasources.DatasourceRef{Type: "prometheus", UID: "${DS_PROMETHEUS}"}
+	req.Equal(prometheus, dashboard.Templatings[0].Datasource)
+	req.Equal("${DS_PROMETHEUS}", dashboard.Annotations[0].Datasource)
+
+	req.True(dashboard.Panels[0].Datasource.IsMixed())
+	req.Equal(prometheus, dashboard.Panels[0].Targets[0].Datasource)
+	req.Equal(&datasources.DatasourceRef{Type: "prometheus", UID: "thanos"}, dashboard.Panels[0].Targets[1].Datasource)
+	req.Equal(&datasources.DatasourceRef{UID: "${DS_PROMETHEUS}"}, dashboard.Panels[1].Datasource)
+	req.Nil(dashboard.Panels[1].Targets[0].Datasource)
+}
+
+func TestConvertLinks(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"links": [
+			{"title": "Pods", "type": "dashboard", "dashUri": "db/kubernetes-pods", "includeVars": true, "keepTime": true},
+			{"title": "Nodes", "type": "dashboard", "dashboard": "nodes"},
+			{"title": "Kubernetes", "type": "dashboards", "tags": ["kubernetes"], "asDropdown": true},
+			{"title": "Docs", "type": "link", "url": "https://kubesphere.io/docs", "targetBlank": true, "tooltip": "Documentation"}
+		],
+		"panels": [{
+			"id": 1,
+			"type": "graph",
+			"links": [{"title": "Logs", "type": "absolute", "url": "/logs?namespace=$namespace"}],
+			"fieldConfig": {"defaults": {"links": [
+				{"title": "Pod", "url": "/d/pod?var-pod=${__field.labels.pod}&${__url_time_range}", "targetBlank": true}
+			]}},
+			"targets": [{"refId": "A", "expr": "up"}]
+		}]
+	}`), false)
+	req.NoError(err)
+
+	req.Equal([]links.Link{
+		{Title: "Pods", Type: links.TypeDashboard, Dashboard: "kubernetes-pods", IncludeVars: true, KeepTime: true},
+		{Title: "Nodes", Type: links.TypeDashboard, Dashboard: "nodes"},
+		{Title: "Kubernetes", Type: links.TypeDashboards, Tags: []string{"kubernetes"}, AsDropdown: true},
+		{Title: "Docs", Type: links.TypeLink, URL: "https://kubesphere.io/docs", TargetBlank: true, Tooltip: "Documentation"},
+	}, dashboard.Links)
+	req.Equal([]links.Link{
+		{Title: "Logs", Type: links.TypeLink, URL: "/logs?namespace=$namespace"},
+		{Title: "Pod", Type: links.TypeLink, URL: "/d/pod?var-pod=${__field.labels.pod}&${__url_time_range}", TargetBlank: true},
+	}, dashboard.Panels[0].Links)
 }
 
 func TestConvertTagAnnotationIgnoresBuiltIn(t *testing.T) {
@@ -176,10 +375,13 @@ func TestConvertLegend(t *testing.T) {
 
 	legend := converter.convertLegend(rawLegend)
 
-	req.ElementsMatch(
-		[]string{"as_table", "to_the_right", "min", "max", "avg", "current", "total", "no_null_series", "no_zero_series"},
-		legend,
-	)
+	req.Equal(&panelsModel.Legend{
+		DisplayMode: panelsModel.LegendDisplayTable,
+		Placement:   panelsModel.LegendPlacementRight,
+		Calcs:       []panelsModel.Calc{"min", "max", "mean", "lastNotNull", "sum"},
+		HideEmpty:   true,
+		HideZero:    true,
+	}, legend)
 }
 
 func TestConvertCanHideLegend(t *testing.T) {
@@ -187,7 +389,7 @@ func TestConvertCanHideLegend(t *testing.T) {
 	converter := NewConverter()
 
 	legend := converter.convertLegend(sdk.Legend{Show: false})
-	req.ElementsMatch([]string{"hide"}, legend)
+	req.Equal(panelsModel.LegendDisplayHidden, legend.DisplayMode)
 }
 
 func TestConvertPanelsAreEmpty(t *testing.T) {
@@ -217,7 +419,7 @@ func TestConvertBarGaugePanel(t *testing.T) {
 	converter := NewConverter()
 	dashboard := &v1alpha2.DashboardSpec{}
 	converter.convertPanels(panels, dashboard, false)
-	req.Equal(*dashboard.Panels[0].CommonPanel.Datasource, datasource)
+	req.Equal(&datasources.DatasourceRef{Name: datasource}, dashboard.Panels[0].CommonPanel.Datasource)
 }
 
 func TestConvertGraphPanel(t *testing.T) {
@@ -238,7 +440,7 @@ func TestConvertGraphPanel(t *testing.T) {
 	converter := NewConverter()
 	dashboard := &v1alpha2.DashboardSpec{}
 	converter.convertPanels(panels, dashboard, false)
-	req.Equal(*dashboard.Panels[0].CommonPanel.Datasource, datasource)
+	req.Equal(&datasources.DatasourceRef{Name: datasource}, dashboard.Panels[0].CommonPanel.Datasource)
 }
 
 func TestConvertSinglestatPanel(t *testing.T) {
@@ -259,7 +461,7 @@ func TestConvertSinglestatPanel(t *testing.T) {
 	converter := NewConverter()
 	dashboard := &v1alpha2.DashboardSpec{}
 	converter.convertPanels(panels, dashboard, false)
-	req.Equal(*dashboard.Panels[0].CommonPanel.Datasource, datasource)
+	req.Equal(&datasources.DatasourceRef{Name: datasource}, dashboard.Panels[0].CommonPanel.Datasource)
 }
 
 func TestConvertTablePanel(t *testing.T) {
@@ -280,7 +482,249 @@ func TestConvertTablePanel(t *testing.T) {
 	converter := NewConverter()
 	dashboard := &v1alpha2.DashboardSpec{}
 	converter.convertPanels(panels, dashboard, false)
-	req.Equal(*dashboard.Panels[0].CommonPanel.Datasource, datasource)
+	req.Equal(&datasources.DatasourceRef{Name: datasource}, dashboard.Panels[0].CommonPanel.Datasource)
+}
+
+func TestConvertTimeSeriesPanel(t *testing.T) {
+	board := `{
+		"panels": [{
+			"id": 2,
+			"type": "timeseries",
+			"title": "Requests",
+			"datasource": "prometheus",
+			"gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+			"fieldConfig": {
+				"defaults": {
+					"unit": "reqps",
+					"decimals": 2,
+					"custom": {
+						"drawStyle": "bars",
+						"lineInterpolation": "smooth",
+						"fillOpacity": 25,
+						"axisPlacement": "right",
+						"stacking": {"mode": "percent", "group": "A"}
+					},
+					"thresholds": {"mode": "absolute", "steps": [{"color": "green", "value": null}]}
+				}
+			},
+			"options": {
+				"legend": {"displayMode": "table", "placement": "bottom", "calcs": ["mean", "median", "max"], "sortBy": "Max", "sortDesc": true}
+			},
+			"targets": [{
+				"expr": "sum(rate(http_requests_total[5m]))",
+				"legendFormat": "{{code}}",
+				"refId": "A"
+			}]
+		}]
+	}`
+
+	req := require.New(t)
+	converter := NewConverter()
+	dashboard, err := converter.convert([]byte(board), false)
+	req.NoError(err)
+	req.Len(dashboard.Panels, 1)
+
+	panel := dashboard.Panels[0]
+	req.Equal("timeseries", panel.Type)
+	req.Equal("reqps", panel.Format)
+	req.Equal(int64(2), *panel.Decimals)
+	req.Equal(&panelsModel.GridPos{W: 12, H: 8}, panel.GridPos)
+	req.Equal(&panelsModel.TimeSeriesPanel{
+		DrawStyle:         "bars",
+		LineInterpolation: "smooth",
+		FillOpacity:       25,
+		StackingMode:      "percent",
+		AxisPlacement:     "right",
+	}, panel.TimeSeriesPanel)
+	req.Equal(&panelsModel.Legend{
+		DisplayMode: "table",
+		Placement:   "bottom",
+		Calcs:       []panelsModel.Calc{"mean", "max"},
+		SortBy:      "max",
+		SortDesc:    true,
+	}, panel.Legend)
+	req.Len(panel.Targets, 1)
+	req.Equal("sum(rate(http_requests_total[5m]))", panel.Targets[0].Expression)
+}
+
+func TestConvertStatAndGaugePanels(t *testing.T) {
+	board := `{
+		"panels": [{
+			"id": 1,
+			"type": "stat",
+			"title": "Pods",
+			"fieldConfig": {"defaults": {"unit": "short", "decimals": 0}},
+			"options": {
+				"orientation": "horizontal",
+				"textMode": "value_and_name",
+				"colorMode": "background",
+				"graphMode": "area",
+				"reduceOptions": {"calcs": ["lastNotNull"], "fields": "", "values": false}
+			},
+			"targets": [{"expr": "count(kube_pod_info)", "refId": "A"}]
+		}, {
+			"id": 2,
+			"type": "gauge",
+			"title": "Disk",
+			"fieldConfig": {"defaults": {"unit": "percent", "decimals": 1}},
+			"options": {
+				"orientation": "auto",
+				"showThresholdLabels": true,
+				"showThresholdMarkers": true,
+				"reduceOptions": {"calcs": ["mean", "max"], "fields": "/.*/", "values": true}
+			},
+			"targets": [{"expr": "node_filesystem_avail_bytes", "refId": "A"}]
+		}]
+	}`
+
+	req := require.New(t)
+	converter := NewConverter()
+	dashboard, err := converter.convert([]byte(board), false)
+	req.NoError(err)
+	req.Len(dashboard.Panels, 2)
+
+	stat := dashboard.Panels[0]
+	req.Equal("stat", stat.Type)
+	req.Equal("short", stat.Format)
+	req.Equal(int64(0), *stat.Decimals)
+	req.Equal(&panelsModel.ReduceOptions{Calcs: []string{"lastNotNull"}}, stat.ReduceOptions)
+	req.Equal(&panelsModel.StatPanel{
+		Orientation: "horizontal",
+		TextMode:    "value_and_name",
+		ColorMode:   "background",
+		GraphMode:   "area",
+	}, stat.StatPanel)
+	req.Len(stat.Targets, 1)
+
+	gauge := dashboard.Panels[1]
+	req.Equal("gauge", gauge.Type)
+	req.Equal("percent", gauge.Format)
+	req.Equal(int64(1), *gauge.Decimals)
+	req.Equal(&panelsModel.ReduceOptions{Calcs: []string{"mean", "max"}, Fields: "/.*/", Values: true}, gauge.ReduceOptions)
+	req.Equal(&panelsModel.GaugePanel{
+		Orientation:          "auto",
+		ShowThresholdLabels:  true,
+		ShowThresholdMarkers: true,
+	}, gauge.GaugePanel)
+	req.Equal("node_filesystem_avail_bytes", gauge.Targets[0].Expression)
+}
+
+func TestConvertFieldConfig(t *testing.T) {
+	board := `{
+		"panels": [{
+			"id": 3,
+			"type": "bargauge",
+			"title": "Usage",
+			"fieldConfig": {
+				"defaults": {
+					"unit": "percentunit",
+					"decimals": 2,
+					"min": 0,
+					"max": 1,
+					"displayName": "${__field.labels.node}",
+					"thresholds": {
+						"mode": "absolute",
+						"steps": [
+							{"color": "green", "value": null},
+							{"color": "orange", "value": 0.7},
+							{"color": "red", "value": 0.9}
+						]
+					},
+					"mappings": [
+						{"type": "value", "options": {"1": {"text": "full", "color": "red", "index": 1}, "0": {"text": "empty", "index": 0}}},
+						{"type": "range", "options": {"from": 0.2, "to": 0.5, "result": {"text": "low"}}},
+						{"type": "regex", "options": {"pattern": "^err", "result": {"text": "error", "color": "red"}}},
+						{"id": 1, "type": 2, "from": "0.5", "to": "0.7", "text": "mid"}
+					]
+				},
+				"overrides": [{
+					"matcher": {"id": "byName", "options": "master"},
+					"properties": [
+						{"id": "unit", "value": "percent"},
+						{"id": "thresholds", "value": {"mode": "percentage", "steps": [{"color": "blue", "value": null}]}}
+					]
+				}]
+			}
+		}]
+	}`
+
+	req := require.New(t)
+	converter := NewConverter()
+	dashboard, err := converter.convert([]byte(board), false)
+	req.NoError(err)
+	req.Len(dashboard.Panels, 1)
+
+	decimals := int64(2)
+	req.Equal(&panelsModel.FieldConfig{
+		Defaults: panelsModel.FieldOptions{
+			Unit:        "percentunit",
+			Decimals:    &decimals,
+			Min:         "0",
+			Max:         "1",
+			DisplayName: "${__field.labels.node}",
+			Thresholds: &panelsModel.Thresholds{
+				Mode: panelsModel.ThresholdsModeAbsolute,
+				Steps: []panelsModel.ThresholdStep{
+					{Color: "green"},
+					{Value: "0.7", Color: "orange"},
+					{Value: "0.9", Color: "red"},
+				},
+			},
+			Mappings: []panelsModel.ValueMapping{
+				{Type: panelsModel.MappingTypeValue, Value: "0", Text: "empty"},
+				{Type: panelsModel.MappingTypeValue, Value: "1", Text: "full", Color: "red"},
+				{Type: panelsModel.MappingTypeRange, From: "0.2", To: "0.5", Text: "low"},
+				{Type: panelsModel.MappingTypeRegex, Pattern: "^err", Text: "error", Color: "red"},
+				{Type: panelsModel.MappingTypeRange, From: "0.5", To: "0.7", Text: "mid"},
+			},
+		},
+		Overrides: []panelsModel.FieldOverride{{
+			Matcher: panelsModel.FieldMatcher{ID: "byName", Options: "master"},
+			Properties: panelsModel.FieldOptions{
+				Unit: "percent",
+				Thresholds: &panelsModel.Thresholds{
+					Mode:  panelsModel.ThresholdsModePercentage,
+					Steps: []panelsModel.ThresholdStep{{Color: "blue"}},
+				},
+			},
+		}},
+	}, dashboard.Panels[0].FieldConfig)
+}
+
+func TestConvertSinglestatThresholds(t *testing.T) {
+	from, to, text := "1", "10", "few"
+	singlestatPanel := &sdk.Panel{
+		CommonPanel: sdk.CommonPanel{
+			Type: "singlestat",
+		},
+		SinglestatPanel: &sdk.SinglestatPanel{
+			Colors:     []string{"green", "orange", "red"},
+			Thresholds: "50, 80",
+			ValueMaps:  []sdk.ValueMap{{Op: "=", TextType: "none", Value: "0"}},
+			RangeMaps:  []*sdk.RangeMap{{From: &from, To: &to, Text: &text}},
+		},
+	}
+
+	req := require.New(t)
+	converter := NewConverter()
+	dashboard := &v1alpha2.DashboardSpec{}
+	converter.convertPanels([]*sdk.Panel{singlestatPanel}, dashboard, false)
+	req.Equal(&panelsModel.FieldConfig{
+		Defaults: panelsModel.FieldOptions{
+			Thresholds: &panelsModel.Thresholds{
+				Mode: panelsModel.ThresholdsModeAbsolute,
+				Steps: []panelsModel.ThresholdStep{
+					{Color: "green"},
+					{Value: "50", Color: "orange"},
+					{Value: "80", Color: "red"},
+				},
+			},
+			Mappings: []panelsModel.ValueMapping{
+				{Type: panelsModel.MappingTypeValue, Value: "0", Text: "none"},
+				{Type: panelsModel.MappingTypeRange, From: "1", To: "10", Text: "few"},
+			},
+		},
+	}, dashboard.Panels[0].FieldConfig)
 }
 
 func TestConvertTextPanel(t *testing.T) {
@@ -306,6 +750,153 @@ func TestConvertTextPanel(t *testing.T) {
 	req.Equal(dashboard.Panels[0].TextPanel.Content, "a markdown content for test")
 }
 
+func TestConvertRowPanels(t *testing.T) {
+	repeat := "instance"
+	panels := []*sdk.Panel{
+		{CommonPanel: sdk.CommonPanel{ID: 1, Title: "ungrouped", Type: "text"}, TextPanel: &sdk.TextPanel{}},
+		{CommonPanel: sdk.CommonPanel{ID: 2, Title: "expanded", Type: "row", Repeat: &repeat}, RowPanel: &sdk.RowPanel{}},
+		{CommonPanel: sdk.CommonPanel{ID: 3, Title: "graph", Type: "graph"}},
+		{CommonPanel: sdk.CommonPanel{ID: 4, Title: "collapsed", Type: "row"}, RowPanel: &sdk.RowPanel{
+			Collapsed: true,
+			Panels: []sdk.Panel{
+				{CommonPanel: sdk.CommonPanel{ID: 5, Title: "table", Type: "table"}, TablePanel: &sdk.TablePanel{}},
+			},
+		}},
+	}
+
+	req := require.New(t)
+	converter := NewConverter()
+	dashboard := &v1alpha2.DashboardSpec{}
+	converter.convertPanels(panels, dashboard, false)
+
+	req.Len(dashboard.Panels, 3)
+	req.Equal("ungrouped", dashboard.Panels[0].Title)
+
+	expanded := dashboard.Panels[1]
+	req.Equal("row", expanded.Type)
+	req.False(expanded.Collapsed)
+	req.Equal("instance", expanded.Repeat)
+	req.Len(expanded.RowPanel.Panels, 1)
+	req.Equal(int64(3), expanded.RowPanel.Panels[0].Id)
+
+	collapsed := dashboard.Panels[2]
+	req.True(collapsed.Collapsed)
+	req.Len(collapsed.RowPanel.Panels, 1)
+	req.Equal("table", collapsed.RowPanel.Panels[0].Type)
+}
+
+func TestConvertRepeat(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [
+			{"id": 1, "type": "graph", "title": "GPU $gpu", "repeat": "gpu", "repeatDirection": "h", "maxPerRow": 3,
+				"targets": [{"refId": "A", "expr": "DCGM_FI_DEV_GPU_TEMP{gpu=\"$gpu\"}"}]},
+			{"id": 2, "type": "graph", "title": "GPU 1", "repeatPanelId": 1, "repeatIteration": 1626000000000,
+				"targets": [{"refId": "A", "expr": "DCGM_FI_DEV_GPU_TEMP{gpu=\"1\"}"}]},
+			{"id": 3, "type": "row", "title": "Node $node", "repeat": "node", "panels": []},
+			{"id": 4, "type": "graph", "title": "Load", "repeat": "cpu", "repeatDirection": "v"},
+			{"id": 5, "type": "row", "title": "Node node-2", "repeatPanelId": 3, "panels": []},
+			{"id": 6, "type": "graph", "title": "Load"}
+		]
+	}`), false)
+	req.NoError(err)
+
+	req.Len(dashboard.Panels, 2)
+	gpu := dashboard.Panels[0]
+	req.Equal("gpu", gpu.Repeat)
+	req.Equal(panelsModel.RepeatDirectionHorizontal, gpu.RepeatDirection)
+	req.Equal(int32(3), gpu.MaxPerRow)
+
+	row := dashboard.Panels[1]
+	req.Equal("node", row.Repeat)
+	req.Empty(row.RepeatDirection)
+	req.Len(row.RowPanel.Panels, 1)
+	req.Equal("cpu", row.RowPanel.Panels[0].Repeat)
+	req.Equal(panelsModel.RepeatDirectionVertical, row.RowPanel.Panels[0].RepeatDirection)
+}
+
+func TestConvertTimeOverrides(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [
+			{"id": 1, "type": "stat", "timeFrom": "24h", "timeShift": "1w", "hideTimeOverride": true,
+				"targets": [{"refId": "A", "expr": "up"}, {"refId": "B", "expr": "up", "timeShift": "1d"}]},
+			{"id": 2, "type": "graph", "timeShift": "1d/d"}
+		]
+	}`), false)
+	req.NoError(err)
+
+	stat := dashboard.Panels[0]
+	req.Equal("24h", stat.TimeFrom)
+	req.Equal("1w", stat.TimeShift)
+	req.True(stat.HideTimeOverride)
+	req.Empty(stat.Targets[0].TimeShift)
+	req.Equal("1d", stat.Targets[1].TimeShift)
+
+	graph := dashboard.Panels[1]
+	req.Empty(graph.TimeFrom)
+	req.Equal("1d/d", graph.TimeShift)
+	req.False(graph.HideTimeOverride)
+}
+
+func TestConvertLegacyRows(t *testing.T) {
+	repeat := "node"
+	rows := []*sdk.Row{
+		nil,
+		{Title: "empty"},
+		{Title: "Nodes", Collapse: true, Repeat: &repeat, Height: "300px", Panels: []sdk.Panel{
+			{CommonPanel: sdk.CommonPanel{ID: 1, Title: "graph", Type: "graph", Span: 8}},
+			{CommonPanel: sdk.CommonPanel{ID: 2, Title: "singlestat", Type: "singlestat", Span: 4, Height: "100px"}},
+			{CommonPanel: sdk.CommonPanel{ID: 3, Title: "table", Type: "table"}},
+		}},
+		{Title: "Pods", Panels: []sdk.Panel{
+			{CommonPanel: sdk.CommonPanel{ID: 4, Title: "graph", Type: "graph", Span: 12}},
+		}},
+	}
+
+	req := require.New(t)
+	converter := NewConverter()
+	dashboard := &v1alpha2.DashboardSpec{}
+	converter.convertRows(rows, dashboard, false)
+
+	req.Len(dashboard.Panels, 2)
+	row := dashboard.Panels[0]
+	req.Equal("Nodes", row.Title)
+	req.Equal("row", row.Type)
+	req.True(row.Collapsed)
+	req.Equal("node", row.Repeat)
+	req.Equal(&panelsModel.GridPos{W: 24, H: 1}, row.GridPos)
+	req.Len(row.RowPanel.Panels, 3)
+	req.Equal(&panelsModel.GridPos{X: 0, Y: 1, W: 16, H: 8}, row.RowPanel.Panels[0].GridPos)
+	req.Equal(&panelsModel.GridPos{X: 16, Y: 1, W: 8, H: 3}, row.RowPanel.Panels[1].GridPos)
+	req.Equal(&panelsModel.GridPos{X: 0, Y: 9, W: 24, H: 8}, row.RowPanel.Panels[2].GridPos)
+
+	row = dashboard.Panels[1]
+	req.Equal(&panelsModel.GridPos{Y: 17, W: 24, H: 1}, row.GridPos)
+	req.Equal(&panelsModel.GridPos{Y: 18, W: 24, H: 7}, row.RowPanel.Panels[0].GridPos)
+}
+
+func TestLegacyGridWidth(t *testing.T) {
+	req := require.New(t)
+	req.Equal(int32(3), legacyGridWidth(1.5))
+	req.Equal(int32(16), legacyGridWidth(8))
+	req.Equal(int32(24), legacyGridWidth(0))
+	req.Equal(int32(24), legacyGridWidth(14))
+}
+
+func TestConvertGridPos(t *testing.T) {
+	req := require.New(t)
+
+	var panel sdk.Panel
+	req.Nil(convertGridPos(panel))
+
+	x, y, w, h := 12, 3, 12, 8
+	panel.GridPos.X, panel.GridPos.Y, panel.GridPos.W, panel.GridPos.H = &x, &y, &w, &h
+	req.Equal(&panelsModel.GridPos{X: 12, Y: 3, W: 12, H: 8}, convertGridPos(panel))
+}
+
 func TestConvertExpr(t *testing.T) {
 	req := require.New(t)
 	testCase := []string{
@@ -318,9 +909,218 @@ func TestConvertExpr(t *testing.T) {
 	req.Equal(newExpr2, "elasticsearch_cluster_health_number_of_pending_tasks")
 }
 
+func TestConvertBuiltinVariables(t *testing.T) {
+	req := require.New(t)
+
+	req.Equal("sum(rate(http_requests_total[$__rate_interval])) / sum(increase(up[$__range]))",
+		convertExpr(`sum(rate(http_requests_total{job="api"}[$__rate_interval])) / sum(increase(up{job="api"}[$__range]))`))
+	req.Equal("rate(node_cpu_seconds_total[$interval])", convertExpr(`rate(node_cpu_seconds_total{mode!="idle"}[$interval])`))
+	req.Equal("rate(x[5m])", convertExpr(`rate(x{job="${job}"}[5m])`))
+	req.Equal("rate(x[${__rate_interval}])", convertExpr(`rate(x{job="api"}[${__rate_interval}])`))
+	req.Equal("sum(rate(x[$__interval])) by (pod)", convertExpr(`sum(rate(x{job=~"${job:regex}", pod="}"}[$__interval])) by (pod)`))
+
+	req.Equal("1m", convertStep(sdk.Target{Interval: "30s", Step: 60}))
+	req.Equal("2m", convertStep(sdk.Target{Step: 120}))
+	req.Equal("", convertStep(sdk.Target{}))
+}
+
 func TestHandleLegendFormat(t *testing.T) {
 	req := require.New(t)
 	testCase := "'{{name}}: {{ type }}'"
 	newExpr := handleLegendFormat(testCase)
 	req.Equal(newExpr, "'{{name}}: {{type}}'")
 }
+
+func TestConvertGraphDisplayOptions(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [{
+			"id": 1, "type": "graph", "title": "Traffic",
+			"lines": true, "fill": 2, "linewidth": 1, "points": true, "pointradius": 0.5,
+			"nullPointMode": "null as zero", "percentage": false, "steppedLine": true,
+			"tooltip": {"shared": true, "sort": 2, "value_type": "individual"},
+			"thresholds": [{"value": 0.9, "op": "gt", "colorMode": "critical", "fill": true, "line": true, "yaxis": "left"}],
+			"seriesOverrides": [
+				{"alias": "/errors/", "yaxis": 2, "color": "#F2495C", "fill": 0, "linewidth": 2, "stack": false},
+				{"alias": "total", "dashes": true, "legend": false, "stack": "A"}
+			],
+			"yaxes": [
+				{"format": "bytes", "logBase": 2, "min": "0", "max": null, "label": "traffic", "show": true},
+				{"format": "percent", "logBase": 1, "min": 0, "max": "1.5", "show": false}
+			]
+		}]
+	}`), false)
+	req.NoError(err)
+
+	graph := dashboard.Panels[0].GraphPanel
+	req.NotNil(graph)
+	req.True(graph.Lines)
+	req.True(graph.Points)
+	req.Equal(int32(2), graph.Fill)
+	req.Equal(int32(1), graph.LineWidth)
+	req.Equal("0.5", graph.PointRadius)
+	req.Equal(panelsModel.NullPointModeNullAsZero, graph.NullPointMode)
+	req.True(graph.SteppedLine)
+	req.Equal(&panelsModel.Tooltip{Mode: panelsModel.TooltipModeShared, Sort: "decreasing", ValueType: "individual"}, graph.Tooltip)
+	req.Equal([]panelsModel.GraphThreshold{
+		{Value: "0.9", Op: "gt", ColorMode: "critical", Fill: true, Line: true, Yaxis: "left"},
+	}, graph.Thresholds)
+
+	zero, two, yes, no := int32(0), int32(2), true, false
+	req.Equal([]panelsModel.SeriesOverride{
+		{Alias: "/errors/", Yaxis: 2, Color: "#F2495C", Fill: &zero, LineWidth: &two, Stack: &no},
+		{Alias: "total", Dashes: &yes, Legend: &no},
+	}, graph.SeriesOverrides)
+
+	req.Equal([]panelsModel.Axis{
+		{Format: "Byte", Min: "0", LogBase: 2, Label: "traffic"},
+		{Format: "percent (0.0-1.0)", Min: "0", Max: "1.5", Show: &no},
+	}, graph.Yaxes)
+}
+
+func TestConvertTableStylesAndTransformations(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [{
+			"id": 1, "type": "table", "title": "Top pods by CPU",
+			"targets": [{"refId": "A", "expr": "topk(10, sum by (pod) (rate(container_cpu_usage_seconds_total[5m])))", "format": "table", "instant": true}],
+			"styles": [
+				{"pattern": "Time", "type": "hidden"},
+				{"pattern": "pod", "alias": "Pod", "type": "string", "link": true,
+					"linkUrl": "/d/pod?var-pod=${__cell}", "linkTooltip": "Pod details", "linkTargetBlank": true},
+				{"pattern": "Value", "alias": "CPU", "type": "number", "unit": "percentunit", "decimals": 2,
+					"colorMode": "cell", "thresholds": ["0.5", "0.8"], "colors": ["green", "orange", "red"]},
+				{"pattern": "/.*/", "type": "date", "dateFormat": "YYYY-MM-DD HH:mm:ss", "thresholds": [], "colors": ["green"]}
+			],
+			"transformations": [
+				{"id": "merge", "options": {}},
+				{"id": "organize", "options": {
+					"excludeByName": {"instance": true},
+					"indexByName": {"pod": 0, "Value": 1},
+					"renameByName": {"Value": "CPU usage"}
+				}},
+				{"id": "filterFieldsByName", "options": {"include": {"names": ["pod"]}}},
+				{"id": "reduce", "options": {"reducers": ["max", "median", "mean"], "mode": "seriesToRows"}}
+			]
+		}]
+	}`), false)
+	req.NoError(err)
+
+	table := dashboard.Panels[0].TablePanel
+	req.NotNil(table)
+	req.Equal([]string{"Time"}, table.HiddenColumns)
+
+	decimals := int64(2)
+	req.Equal([]panelsModel.ColumnStyle{
+		{Pattern: "pod", Alias: "Pod", Type: "string", Link: &panelsModel.ColumnLink{
+			URL: "/d/pod?var-pod=${__cell}", Tooltip: "Pod details", TargetBlank: true,
+		}},
+		{Pattern: "Value", Alias: "CPU", Type: "number", Unit: "percentunit", Decimals: &decimals,
+			ColorMode: "cell", Thresholds: []string{"0.5", "0.8"}, Colors: []string{"green", "orange", "red"}},
+		{Pattern: "/.*/", Type: "date", DateFormat: "YYYY-MM-DD HH:mm:ss"},
+	}, table.Styles)
+
+	req.Equal([]panelsModel.Transformation{
+		{ID: "merge"},
+		{ID: "organize", Options: &panelsModel.TransformationOptions{
+			ExcludeByName: map[string]bool{"instance": true},
+			IndexByName:   map[string]int32{"pod": 0, "Value": 1},
+			RenameByName:  map[string]string{"Value": "CPU usage"},
+		}},
+		{ID: "reduce", Options: &panelsModel.TransformationOptions{
+			Reducers: []panelsModel.Calc{panelsModel.CalcMax, panelsModel.CalcMean},
+			Mode:     "seriesToRows",
+		}},
+	}, table.Transformations)
+}
+
+func TestConvertHeatmapPanel(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [{
+			"id": 1, "type": "heatmap", "title": "Request latency",
+			"datasource": "prometheus",
+			"targets": [{"refId": "A", "expr": "sum by (le) (increase(http_request_duration_seconds_bucket[$__interval]))", "format": "heatmap", "legendFormat": "{{le}}"}],
+			"dataFormat": "tsbuckets",
+			"color": {"mode": "spectrum", "colorScheme": "interpolateOranges", "colorScale": "sqrt", "exponent": 0.5, "cardColor": "#b4ff00"},
+			"hideZeroBuckets": true,
+			"highlightCards": true,
+			"reverseYBuckets": false,
+			"tooltip": {"show": true, "showHistogram": true},
+			"yAxis": {"format": "s", "decimals": 1, "logBase": 1, "min": "0", "max": null, "show": true, "splitFactor": null},
+			"yBucketBound": "upper",
+			"yBucketNumber": null,
+			"yBucketSize": 0.25
+		}]
+	}`), false)
+	req.NoError(err)
+	req.Len(dashboard.Panels, 1)
+
+	panel := dashboard.Panels[0]
+	req.Equal("heatmap", panel.Type)
+	req.Len(panel.Targets, 1)
+	req.Equal(panelsModel.TargetFormatHeatmap, panel.Targets[0].Format)
+	req.Equal(&panelsModel.HeatmapPanel{
+		DataFormat: panelsModel.HeatmapDataFormatTSBuckets,
+		Color: &panelsModel.HeatmapColor{
+			Mode:        panelsModel.HeatmapColorModeSpectrum,
+			ColorScheme: "interpolateOranges",
+			CardColor:   "#b4ff00",
+			ColorScale:  "sqrt",
+			Exponent:    "0.5",
+		},
+		HideZeroBuckets:  true,
+		HighlightCards:   true,
+		YAxis:            panelsModel.Axis{Format: "s", Decimals: 1, Min: "0"},
+		YBucketBound:     "upper",
+		YBucketSize:      "0.25",
+		TooltipHistogram: true,
+	}, panel.HeatmapPanel)
+}
+
+func TestConvertHeatmapTooltip(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [
+			{"id": 1, "type": "heatmap", "title": "Without a tooltip"},
+			{"id": 2, "type": "heatmap", "title": "Hidden tooltip", "tooltip": {"show": false}}
+		]
+	}`), false)
+	req.NoError(err)
+	req.Len(dashboard.Panels, 2)
+	req.False(dashboard.Panels[0].HeatmapPanel.HideTooltip)
+	req.True(dashboard.Panels[1].HeatmapPanel.HideTooltip)
+}
+
+func TestConvertPluginPanel(t *testing.T) {
+	req := require.New(t)
+
+	dashboard, err := NewConverter().convert([]byte(`{
+		"panels": [{
+			"id": 1, "type": "grafana-piechart-panel", "title": "Pods by phase",
+			"gridPos": {"x": 0, "y": 0, "w": 8, "h": 6},
+			"pluginVersion": "1.6.2",
+			"targets": [{"refId": "A", "expr": "sum by (phase) (kube_pod_status_phase)"}],
+			"pieType": "donut",
+			"valueName": "current",
+			"options": {"strokeWidth": 1}
+		}]
+	}`), false)
+	req.NoError(err)
+	req.Len(dashboard.Panels, 1)
+
+	panel := dashboard.Panels[0]
+	req.Equal("grafana-piechart-panel", panel.Type)
+	req.Len(panel.Targets, 1)
+	req.JSONEq(`{"pieType": "donut", "valueName": "current", "options": {"strokeWidth": 1}}`, string(panel.Custom))
+
+	b, err := json.Marshal(panel)
+	req.NoError(err)
+	var decoded panelsModel.Panel
+	req.NoError(json.Unmarshal(b, &decoded))
+	req.Equal(panel, &decoded)
+}
@@ -0,0 +1,252 @@
+package converter
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana-tools/sdk"
+	"github.com/mitchellh/mapstructure"
+	panelsModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// the field config as grafana stores it
+type fieldConfig struct {
+	Defaults  map[string]interface{}
+	Overrides []struct {
+		Matcher struct {
+			ID      string
+			Options interface{}
+		}
+		Properties []struct {
+			ID    string
+			Value interface{}
+		}
+	}
+}
+
+// convertFieldConfig converts the field config of the given panel, nil when it has none
+func (converter *Converter) convertFieldConfig(panel sdk.Panel) *panelsModel.FieldConfig {
+	raw := converter.rawPanels[panel.ID].FieldConfig
+	if raw == nil {
+		return nil
+	}
+
+	var config fieldConfig
+	if err := mapstructure.Decode(raw, &config); err != nil {
+		return nil
+	}
+
+	converted := &panelsModel.FieldConfig{
+		Defaults: convertFieldOptions(config.Defaults),
+	}
+	for _, override := range config.Overrides {
+		// override properties are the field options one at a time
+		properties := make(map[string]interface{})
+		for _, property := range override.Properties {
+			properties[property.ID] = property.Value
+		}
+		converted.Overrides = append(converted.Overrides, panelsModel.FieldOverride{
+			Matcher: panelsModel.FieldMatcher{
+				ID:      override.Matcher.ID,
+				Options: numberString(override.Matcher.Options),
+			},
+			Properties: convertFieldOptions(properties),
+		})
+	}
+
+	if len(converted.Overrides) == 0 && isEmptyFieldOptions(converted.Defaults) {
+		return nil
+	}
+	return converted
+}
+
+func convertFieldOptions(options map[string]interface{}) panelsModel.FieldOptions {
+	converted := panelsModel.FieldOptions{
+		Min: numberString(options["min"]),
+		Max: numberString(options["max"]),
+	}
+	converted.Unit, _ = options["unit"].(string)
+	converted.DisplayName, _ = options["displayName"].(string)
+	if decimals, ok := options["decimals"].(float64); ok {
+		converted.Decimals = intToInt64point(int(decimals))
+	}
+
+	var thresholds struct {
+		Mode  string
+		Steps []struct {
+			Color string
+			Value interface{}
+		}
+	}
+	if err := mapstructure.Decode(options["thresholds"], &thresholds); err == nil && len(thresholds.Steps) > 0 {
+		converted.Thresholds = &panelsModel.Thresholds{Mode: thresholds.Mode}
+		for _, step := range thresholds.Steps {
+			converted.Thresholds.Steps = append(converted.Thresholds.Steps, panelsModel.ThresholdStep{
+				Value: numberString(step.Value),
+				Color: step.Color,
+			})
+		}
+	}
+
+	if mappings, ok := options["mappings"].([]interface{}); ok {
+		for _, mapping := range mappings {
+			if m, ok := mapping.(map[string]interface{}); ok {
+				converted.Mappings = append(converted.Mappings, convertValueMapping(m)...)
+			}
+		}
+	}
+
+	return converted
+}
+
+// convertValueMapping reads both the grafana 8 mappings, which carry their matches in options,
+// and the older ones, which are numbered 1 for values and 2 for ranges
+func convertValueMapping(mapping map[string]interface{}) []panelsModel.ValueMapping {
+	type result struct {
+		Text  string
+		Color string
+	}
+
+	switch mapping["type"] {
+	case panelsModel.MappingTypeValue:
+		var options map[string]struct {
+			Text  string
+			Color string
+			Index int
+		}
+		if err := mapstructure.Decode(mapping["options"], &options); err != nil {
+			return nil
+		}
+		var values []string
+		for value := range options {
+			values = append(values, value)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if options[values[i]].Index != options[values[j]].Index {
+				return options[values[i]].Index < options[values[j]].Index
+			}
+			return values[i] < values[j]
+		})
+		var converted []panelsModel.ValueMapping
+		for _, value := range values {
+			converted = append(converted, panelsModel.ValueMapping{
+				Type:  panelsModel.MappingTypeValue,
+				Value: value,
+				Text:  options[value].Text,
+				Color: options[value].Color,
+			})
+		}
+		return converted
+	case panelsModel.MappingTypeRange:
+		var options struct {
+			From   interface{}
+			To     interface{}
+			Result result
+		}
+		if err := mapstructure.Decode(mapping["options"], &options); err != nil {
+			return nil
+		}
+		return []panelsModel.ValueMapping{{
+			Type:  panelsModel.MappingTypeRange,
+			From:  numberString(options.From),
+			To:    numberString(options.To),
+			Text:  options.Result.Text,
+			Color: options.Result.Color,
+		}}
+	case panelsModel.MappingTypeRegex:
+		var options struct {
+			Pattern string
+			Result  result
+		}
+		if err := mapstructure.Decode(mapping["options"], &options); err != nil {
+			return nil
+		}
+		return []panelsModel.ValueMapping{{
+			Type:    panelsModel.MappingTypeRegex,
+			Pattern: options.Pattern,
+			Text:    options.Result.Text,
+			Color:   options.Result.Color,
+		}}
+	case float64(1):
+		text, _ := mapping["text"].(string)
+		return []panelsModel.ValueMapping{{
+			Type:  panelsModel.MappingTypeValue,
+			Value: numberString(mapping["value"]),
+			Text:  text,
+		}}
+	case float64(2):
+		text, _ := mapping["text"].(string)
+		return []panelsModel.ValueMapping{{
+			Type: panelsModel.MappingTypeRange,
+			From: numberString(mapping["from"]),
+			To:   numberString(mapping["to"]),
+			Text: text,
+		}}
+	}
+	return nil
+}
+
+// convertLegacyFieldConfig moves the thresholds and value mappings of a singlestat into a field config.
+// The thresholds are a comma separated list of values, colored by the colors following the first one.
+func convertLegacyFieldConfig(thresholds string, colors []string, valueMaps []sdk.ValueMap, rangeMaps []*sdk.RangeMap) *panelsModel.FieldConfig {
+	var options panelsModel.FieldOptions
+
+	if thresholds = strings.TrimSpace(thresholds); thresholds != "" && len(colors) > 0 {
+		options.Thresholds = &panelsModel.Thresholds{
+			Mode:  panelsModel.ThresholdsModeAbsolute,
+			Steps: []panelsModel.ThresholdStep{{Color: colors[0]}},
+		}
+		for i, value := range strings.Split(thresholds, ",") {
+			if i+1 >= len(colors) {
+				break
+			}
+			options.Thresholds.Steps = append(options.Thresholds.Steps, panelsModel.ThresholdStep{
+				Value: strings.TrimSpace(value),
+				Color: colors[i+1],
+			})
+		}
+	}
+
+	for _, valueMap := range valueMaps {
+		options.Mappings = append(options.Mappings, panelsModel.ValueMapping{
+			Type:  panelsModel.MappingTypeValue,
+			Value: valueMap.Value,
+			Text:  valueMap.TextType,
+		})
+	}
+	for _, rangeMap := range rangeMaps {
+		if rangeMap == nil {
+			continue
+		}
+		options.Mappings = append(options.Mappings, panelsModel.ValueMapping{
+			Type: panelsModel.MappingTypeRange,
+			From: pointToString(rangeMap.From),
+			To:   pointToString(rangeMap.To),
+			Text: pointToString(rangeMap.Text),
+		})
+	}
+
+	if isEmptyFieldOptions(options) {
+		return nil
+	}
+	return &panelsModel.FieldConfig{Defaults: options}
+}
+
+func isEmptyFieldOptions(options panelsModel.FieldOptions) bool {
+	return reflect.DeepEqual(options, panelsModel.FieldOptions{})
+}
+
+// numberString formats the numbers grafana stores as json numbers or strings, nil gives an empty string
+func numberString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package converter
+
+import (
+	"strconv"
+
+	"github.com/grafana-tools/sdk"
+	panelsModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// the orders of the series of a shared tooltip, by the sort number of grafana
+var tooltipSorts = []string{"none", "increasing", "decreasing"}
+
+// convertYaxis converts a y-axis of a graph
+func convertYaxis(axis sdk.Axis) panelsModel.Axis {
+	converted := panelsModel.Axis{
+		Format:   handleGraphFormat(axis.Format),
+		Decimals: int64(axis.Decimals),
+		Label:    axis.Label,
+	}
+	if axis.Min != nil && axis.Min.Valid {
+		converted.Min = strconv.FormatFloat(axis.Min.Value, 'f', -1, 64)
+	}
+	if axis.Max != nil && axis.Max.Valid {
+		converted.Max = strconv.FormatFloat(axis.Max.Value, 'f', -1, 64)
+	}
+	// a linear scale is the default
+	if axis.LogBase > 1 {
+		converted.LogBase = int32(axis.LogBase)
+	}
+	// grafana writes whether each axis is shown, only hidden ones are kept
+	if !axis.Show {
+		converted.Show = &axis.Show
+	}
+	return converted
+}
+
+// convertTooltip converts the tooltip of a graph, none when grafana didn't write one
+func convertTooltip(tooltip sdk.Tooltip) *panelsModel.Tooltip {
+	if tooltip == (sdk.Tooltip{}) {
+		return nil
+	}
+	converted := &panelsModel.Tooltip{
+		Mode:      panelsModel.TooltipModeSingle,
+		ValueType: tooltip.ValueType,
+	}
+	if tooltip.Shared {
+		converted.Mode = panelsModel.TooltipModeShared
+	}
+	if tooltip.Sort > 0 && tooltip.Sort < len(tooltipSorts) {
+		converted.Sort = tooltipSorts[tooltip.Sort]
+	}
+	return converted
+}
+
+// convertGraphThresholds converts the thresholds of a graph
+func convertGraphThresholds(thresholds []sdk.Threshold) []panelsModel.GraphThreshold {
+	var converted []panelsModel.GraphThreshold
+	for _, threshold := range thresholds {
+		converted = append(converted, panelsModel.GraphThreshold{
+			Value:     strconv.FormatFloat(float64(threshold.Value), 'f', -1, 32),
+			Op:        threshold.Op,
+			ColorMode: threshold.ColorMode,
+			Fill:      threshold.Fill,
+			Line:      threshold.Line,
+			FillColor: threshold.FillColor,
+			LineColor: threshold.LineColor,
+			Yaxis:     threshold.Yaxis,
+		})
+	}
+	return converted
+}
+
+// convertSeriesOverrides converts the series overrides of a graph, leaving out the ones without an alias
+func convertSeriesOverrides(overrides []sdk.SeriesOverride) []panelsModel.SeriesOverride {
+	var converted []panelsModel.SeriesOverride
+	for _, override := range overrides {
+		if override.Alias == "" {
+			continue
+		}
+		c := panelsModel.SeriesOverride{
+			Alias:     override.Alias,
+			Color:     stringValue(override.Color),
+			Fill:      intpointToInt32point(override.Fill),
+			LineWidth: intpointToInt32point(override.LineWidth),
+			Bars:      override.Bars,
+			Lines:     override.Lines,
+			Dashes:    override.Dashes,
+			Legend:    override.Legend,
+		}
+		if override.YAxis != nil && (*override.YAxis == 1 || *override.YAxis == 2) {
+			c.Yaxis = int32(*override.YAxis)
+		}
+		// a stack group name, such as A, stacks the series with the others of the group, which can't be told apart here
+		if stack := override.Stack; stack != nil && stack.Value == "" {
+			c.Stack = &stack.Flag
+		}
+		if override.NullPointMode != nil {
+			c.NullPointMode = nullPointMode(*override.NullPointMode)
+		}
+		converted = append(converted, c)
+	}
+	return converted
+}
+
+// nullPointMode keeps the null point modes grafana knows of
+func nullPointMode(mode string) string {
+	switch mode {
+	case panelsModel.NullPointModeConnected, panelsModel.NullPointModeNull, panelsModel.NullPointModeNullAsZero:
+		return mode
+	}
+	return ""
+}
+
+// floatString formats a number, leaving zero out
+func floatString(value float32) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(value), 'f', -1, 32)
+}
@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/grafana-tools/sdk"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+)
+
+// convertLinks converts the links of a dashboard or a panel
+func convertLinks(sdkLinks []sdk.Link) []links.Link {
+	var converted []links.Link
+	for _, link := range sdkLinks {
+		l := links.Link{
+			Title:       link.Title,
+			Type:        links.TypeLink,
+			Tags:        link.Tags,
+			IncludeVars: link.IncludeVars,
+			AsDropdown:  boolValue(link.AsDropdown),
+			TargetBlank: boolValue(link.TargetBlank),
+			KeepTime:    boolValue(link.KeepTime),
+			Icon:        stringValue(link.Icon),
+			Tooltip:     stringValue(link.Tooltip),
+			Params:      stringValue(link.Params),
+		}
+		switch link.Type {
+		case links.TypeDashboards:
+			l.Type = links.TypeDashboards
+		case links.TypeDashboard:
+			l.Type = links.TypeDashboard
+			l.Dashboard = linkedDashboardName(link)
+		default:
+			// link, or absolute for the links of panels
+			l.URL = stringValue(link.URL)
+		}
+		converted = append(converted, l)
+	}
+	return converted
+}
+
+// linkedDashboardName is the name of the dashboard a link points at, assuming it is converted under
+// the slug of its grafana uri, such as db/<slug>. Grafana dashboards linked to by title only keep it.
+func linkedDashboardName(link sdk.Link) string {
+	if uri := stringValue(link.DashURI); uri != "" {
+		return uri[strings.LastIndex(uri, "/")+1:]
+	}
+	return stringValue(link.Dashboard)
+}
+
+// convertPanelLinks converts the links of the panel menu, followed by the data links of its series,
+// which the sdk leaves out of the field config
+func (converter *Converter) convertPanelLinks(panel sdk.Panel) []links.Link {
+	converted := convertLinks(panel.Links)
+
+	defaults, _ := converter.rawPanels[panel.ID].FieldConfig["defaults"].(map[string]interface{})
+	dataLinks, _ := defaults["links"].([]interface{})
+	for _, dataLink := range dataLinks {
+		m, ok := dataLink.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		l := links.Link{Type: links.TypeLink}
+		l.Title, _ = m["title"].(string)
+		l.URL, _ = m["url"].(string)
+		l.TargetBlank, _ = m["targetBlank"].(bool)
+		converted = append(converted, l)
+	}
+	return converted
+}
+
+func boolValue(p *bool) bool {
+	return p != nil && *p
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
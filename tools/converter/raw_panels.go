@@ -0,0 +1,162 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+)
+
+// the parts of a panel the sdk drops: it models fieldConfig for a few panel types only,
+// and without thresholds, mappings or overrides, leaves out some target options, repeat
+// options, transformations and the time overrides of most panel types, reads datasources as names only
+// and can not tell a hidden tooltip from a missing one
+type rawPanel struct {
+	ID               uint                       `json:"id"`
+	Datasource       *datasources.DatasourceRef `json:"datasource"`
+	FieldConfig      map[string]interface{}     `json:"fieldConfig"`
+	Targets          []rawTarget                `json:"targets"`
+	Panels           []rawPanel                 `json:"panels"`
+	RepeatDirection  string                     `json:"repeatDirection"`
+	MaxPerRow        int32                      `json:"maxPerRow"`
+	TimeFrom         string                     `json:"timeFrom"`
+	TimeShift        string                     `json:"timeShift"`
+	HideTimeOverride bool                       `json:"hideTimeOverride"`
+	Transformations  []rawTransformation        `json:"transformations"`
+	Tooltip          rawTooltip                 `json:"tooltip"`
+}
+
+type rawTarget struct {
+	RefID      string                     `json:"refId"`
+	Datasource *datasources.DatasourceRef `json:"datasource"`
+	Range      bool                       `json:"range"`
+	Exemplar   bool                       `json:"exemplar"`
+	TimeShift  string                     `json:"timeShift"`
+}
+
+// the options of a transformation are read once its id is known, as they differ from one id to another
+type rawTransformation struct {
+	ID      string          `json:"id"`
+	Options json.RawMessage `json:"options"`
+}
+
+// the sdk reads a missing show flag of a tooltip as false, which hides it
+type rawTooltip struct {
+	Show *bool `json:"show"`
+}
+
+type rawVariable struct {
+	Name       string                     `json:"name"`
+	Datasource *datasources.DatasourceRef `json:"datasource"`
+}
+
+// the inputs of the dashboards grafana exports for sharing
+type rawInput struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	PluginID    string `json:"pluginId"`
+}
+
+// readRawPanels indexes the board panels, including the ones in rows, by panel ID
+func readRawPanels(content []byte) (map[uint]rawPanel, error) {
+	var board struct {
+		Panels []rawPanel `json:"panels"`
+		Rows   []struct {
+			Panels []rawPanel `json:"panels"`
+		} `json:"rows"`
+	}
+	if err := json.Unmarshal(content, &board); err != nil {
+		return nil, err
+	}
+
+	rawPanels := make(map[uint]rawPanel)
+	var index func(pls []rawPanel)
+	index = func(pls []rawPanel) {
+		for _, panel := range pls {
+			if panel.ID != 0 {
+				rawPanels[panel.ID] = panel
+			}
+			index(panel.Panels)
+		}
+	}
+	index(board.Panels)
+	for _, row := range board.Rows {
+		index(row.Panels)
+	}
+	return rawPanels, nil
+}
+
+// readRawBoard reads the datasource inputs of the board, and its variables by name
+func readRawBoard(content []byte) ([]datasources.Input, map[string]rawVariable, error) {
+	var board struct {
+		Inputs     []rawInput `json:"__inputs"`
+		Templating struct {
+			List []rawVariable `json:"list"`
+		} `json:"templating"`
+	}
+	if err := json.Unmarshal(content, &board); err != nil {
+		return nil, nil, err
+	}
+
+	var inputs []datasources.Input
+	for _, input := range board.Inputs {
+		if input.Type != datasources.InputType {
+			continue
+		}
+		inputs = append(inputs, datasources.Input{
+			Name:        input.Name,
+			Label:       input.Label,
+			Description: input.Description,
+			PluginID:    input.PluginID,
+		})
+	}
+
+	variables := make(map[string]rawVariable, len(board.Templating.List))
+	for _, variable := range board.Templating.List {
+		variables[variable.Name] = variable
+	}
+	return inputs, variables, nil
+}
+
+// legacyDatasources writes the datasources grafana 8.3 and later refer to with an object
+// as their uid, which the sdk can read
+func legacyDatasources(content []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var board interface{}
+	if err := decoder.Decode(&board); err != nil {
+		return nil, err
+	}
+
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, field := range v {
+				if ref, ok := field.(map[string]interface{}); ok && key == "datasource" {
+					v[key], _ = ref["uid"].(string)
+					continue
+				}
+				walk(field)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(board)
+	return json.Marshal(board)
+}
+
+// rawTarget returns the raw target of the panel with the given refId
+func (converter *Converter) rawTarget(panelID uint, refID string) rawTarget {
+	for _, target := range converter.rawPanels[panelID].Targets {
+		if target.RefID == refID {
+			return target
+		}
+	}
+	return rawTarget{}
+}
@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/grafana-tools/sdk"
+	panelsModel "kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// convertColumnStyles converts the column styles of a table. The columns of hidden styles
+// are returned apart, as hidden columns.
+func convertColumnStyles(styles []sdk.ColumnStyle) ([]panelsModel.ColumnStyle, []string) {
+	var converted []panelsModel.ColumnStyle
+	var hidden []string
+	for _, style := range styles {
+		if style.Pattern == "" {
+			continue
+		}
+		if style.Type == "hidden" {
+			hidden = append(hidden, style.Pattern)
+			continue
+		}
+
+		c := panelsModel.ColumnStyle{
+			Pattern:    style.Pattern,
+			Alias:      stringValue(style.Alias),
+			Unit:       stringValue(style.Unit),
+			Decimals:   uintpointToInt64point(style.Decimals),
+			DateFormat: stringValue(style.DateFormat),
+		}
+		switch style.Type {
+		case panelsModel.ColumnTypeNumber, panelsModel.ColumnTypeString, panelsModel.ColumnTypeDate:
+			c.Type = style.Type
+		}
+		// grafana writes thresholds and colors even when the values aren't colored
+		if mode := stringValue(style.ColorMode); mode == "cell" || mode == "value" || mode == "row" {
+			c.ColorMode = mode
+			if style.Thresholds != nil {
+				c.Thresholds = *style.Thresholds
+			}
+			if style.Colors != nil {
+				c.Colors = *style.Colors
+			}
+		}
+		if url := stringValue(style.LinkUrl); style.Link && url != "" {
+			c.Link = &panelsModel.ColumnLink{
+				URL:         url,
+				Tooltip:     stringValue(style.LinkTooltip),
+				TargetBlank: style.LinkTargetBlank,
+			}
+		}
+		converted = append(converted, c)
+	}
+	return converted, hidden
+}
+
+// convertTransformations converts the merge, organize and reduce transformations of a panel,
+// leaving out the others
+func (converter *Converter) convertTransformations(panel sdk.Panel) []panelsModel.Transformation {
+	var converted []panelsModel.Transformation
+	for _, transformation := range converter.rawPanels[panel.ID].Transformations {
+		switch transformation.ID {
+		case panelsModel.TransformationMerge, panelsModel.TransformationOrganize, panelsModel.TransformationReduce:
+		default:
+			continue
+		}
+
+		c := panelsModel.Transformation{ID: transformation.ID}
+		if raw := transformation.Options; len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+			var options panelsModel.TransformationOptions
+			if err := json.Unmarshal(raw, &options); err != nil {
+				continue
+			}
+			options.Reducers = knownCalcs(options.Reducers)
+			if !reflect.DeepEqual(options, panelsModel.TransformationOptions{}) {
+				c.Options = &options
+			}
+		}
+		converted = append(converted, c)
+	}
+	return converted
+}
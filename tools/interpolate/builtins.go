@@ -0,0 +1,193 @@
+package interpolate
+
+import (
+	"strconv"
+	gotime "time"
+
+	"github.com/prometheus/common/model"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+)
+
+// Built-in variables, computed for every query
+const (
+	// Resolution of the query, such as 30s
+	VarInterval = "__interval"
+	// Resolution of the query in milliseconds
+	VarIntervalMs = "__interval_ms"
+	// Time range of the dashboard, such as 3600s
+	VarRange = "__range"
+	// Time range of the dashboard in seconds
+	VarRangeS = "__range_s"
+	// Time range of the dashboard in milliseconds
+	VarRangeMs = "__range_ms"
+	// Range to use in rate and increase, long enough to hold at least four samples
+	VarRateInterval = "__rate_interval"
+)
+
+const (
+	// DefaultRange is the time range of a dashboard which sets none
+	DefaultRange = 6 * gotime.Hour
+	// DefaultScrapeInterval is the scrape interval of prometheus when it is not known
+	DefaultScrapeInterval = 15 * gotime.Second
+	// DashboardWidth is the width of a dashboard in pixels, to compute the width of panels from
+	DashboardWidth = 1920
+)
+
+// BuiltinOptions are what the built-in variables of a query are computed from
+type BuiltinOptions struct {
+	// Time range of the query
+	Range gotime.Duration
+	// Number of points of the series, the width of the panel in pixels
+	MaxDataPoints int
+	// Lower bound of the resolution, the step of the target
+	MinInterval gotime.Duration
+	// Scrape interval of the datasource, DefaultScrapeInterval when zero
+	ScrapeInterval gotime.Duration
+	// Resolution as a fraction of the computed one: the interval is multiplied by the factor
+	IntervalFactor int
+}
+
+// Builtins are the values of the built-in variables of a query
+type Builtins struct {
+	Interval     gotime.Duration
+	Range        gotime.Duration
+	RateInterval gotime.Duration
+}
+
+// NewBuiltins computes the built-in variables the way grafana does: the time range is divided
+// in MaxDataPoints and rounded to a human friendly interval, no lower than MinInterval and
+// the scrape interval, then multiplied by IntervalFactor.
+func NewBuiltins(opts BuiltinOptions) Builtins {
+	scrape := opts.ScrapeInterval
+	if scrape <= 0 {
+		scrape = DefaultScrapeInterval
+	}
+	minInterval := opts.MinInterval
+	if minInterval <= 0 {
+		minInterval = scrape
+	}
+
+	interval := minInterval
+	if opts.MaxDataPoints > 0 {
+		if i := roundInterval(opts.Range / gotime.Duration(opts.MaxDataPoints)); i > interval {
+			interval = i
+		}
+	}
+	if opts.IntervalFactor > 1 {
+		interval *= gotime.Duration(opts.IntervalFactor)
+	}
+
+	rateInterval := interval + scrape
+	if rateInterval < 4*scrape {
+		rateInterval = 4 * scrape
+	}
+
+	return Builtins{
+		Interval:     interval,
+		Range:        opts.Range,
+		RateInterval: rateInterval,
+	}
+}
+
+// values returns the built-in variables by name
+func (b Builtins) values() map[string]string {
+	rangeS := strconv.FormatInt(int64(b.Range/gotime.Second), 10)
+	return map[string]string{
+		VarInterval:     model.Duration(b.Interval).String(),
+		VarIntervalMs:   strconv.FormatInt(int64(b.Interval/gotime.Millisecond), 10),
+		VarRange:        rangeS + "s",
+		VarRangeS:       rangeS,
+		VarRangeMs:      strconv.FormatInt(int64(b.Range/gotime.Millisecond), 10),
+		VarRateInterval: model.Duration(b.RateInterval).String(),
+	}
+}
+
+// the intervals a computed one is rounded to, with the upper bound of the computed ones
+var roundedIntervals = []struct {
+	max      gotime.Duration
+	interval gotime.Duration
+}{
+	{10 * gotime.Millisecond, gotime.Millisecond},
+	{15 * gotime.Millisecond, 10 * gotime.Millisecond},
+	{35 * gotime.Millisecond, 20 * gotime.Millisecond},
+	{75 * gotime.Millisecond, 50 * gotime.Millisecond},
+	{150 * gotime.Millisecond, 100 * gotime.Millisecond},
+	{350 * gotime.Millisecond, 200 * gotime.Millisecond},
+	{750 * gotime.Millisecond, 500 * gotime.Millisecond},
+	{1500 * gotime.Millisecond, gotime.Second},
+	{3500 * gotime.Millisecond, 2 * gotime.Second},
+	{7500 * gotime.Millisecond, 5 * gotime.Second},
+	{12500 * gotime.Millisecond, 10 * gotime.Second},
+	{17500 * gotime.Millisecond, 15 * gotime.Second},
+	{25 * gotime.Second, 20 * gotime.Second},
+	{45 * gotime.Second, 30 * gotime.Second},
+	{90 * gotime.Second, gotime.Minute},
+	{210 * gotime.Second, 2 * gotime.Minute},
+	{450 * gotime.Second, 5 * gotime.Minute},
+	{750 * gotime.Second, 10 * gotime.Minute},
+	{1050 * gotime.Second, 15 * gotime.Minute},
+	{1500 * gotime.Second, 20 * gotime.Minute},
+	{2700 * gotime.Second, 30 * gotime.Minute},
+	{90 * gotime.Minute, gotime.Hour},
+	{150 * gotime.Minute, 2 * gotime.Hour},
+	{270 * gotime.Minute, 3 * gotime.Hour},
+	{9 * gotime.Hour, 6 * gotime.Hour},
+	{24 * gotime.Hour, 12 * gotime.Hour},
+	{7 * 24 * gotime.Hour, 24 * gotime.Hour},
+	{21 * 24 * gotime.Hour, 7 * 24 * gotime.Hour},
+	{365 * 24 * gotime.Hour, 30 * 24 * gotime.Hour},
+}
+
+// roundInterval rounds an interval like grafana does
+func roundInterval(interval gotime.Duration) gotime.Duration {
+	for _, r := range roundedIntervals {
+		if interval <= r.max {
+			return r.interval
+		}
+	}
+	return 365 * 24 * gotime.Hour
+}
+
+// TimeRange resolves the time range of a dashboard at now, in the timezone of the dashboard.
+// A range which doesn't resolve, or ends before it starts, is replaced by the DefaultRange up to now.
+func TimeRange(t time.Time, timezone string, now gotime.Time) (from gotime.Time, to gotime.Time) {
+	from, to, err := t.Resolve(now, timezone)
+	if err != nil || !from.Before(to) {
+		return now.Add(-DefaultRange), now
+	}
+	return from, to
+}
+
+// PanelTimeRange resolves the time range of a panel at now: the time range of the dashboard, or the
+// relative time of the panel such as 24h, moved back by the time shift of the panel such as 1w.
+// Overrides which don't resolve are ignored.
+func PanelTimeRange(t time.Time, timezone, timeFrom, timeShift string, now gotime.Time) (from gotime.Time, to gotime.Time) {
+	from, to, err := t.ResolvePanel(now, timezone, timeFrom, timeShift)
+	if err != nil || !from.Before(to) {
+		return TimeRange(t, timezone, now)
+	}
+	return from, to
+}
+
+// ShiftTimeRange moves a time range back by the time shift of a target, such as 1w.
+// A time shift which doesn't resolve is ignored.
+func ShiftTimeRange(from, to gotime.Time, timeShift string) (gotime.Time, gotime.Time) {
+	shiftedFrom, err := time.Shift(from, timeShift, false)
+	if err != nil {
+		return from, to
+	}
+	shiftedTo, err := time.Shift(to, timeShift, true)
+	if err != nil || !shiftedFrom.Before(shiftedTo) {
+		return from, to
+	}
+	return shiftedFrom, shiftedTo
+}
+
+// PanelWidth returns the width of a panel in pixels, the whole dashboard when it has no position
+func PanelWidth(pos *panels.GridPos) int {
+	if pos == nil || pos.W <= 0 || pos.W > panels.GridColumns {
+		return DashboardWidth
+	}
+	return int(pos.W) * DashboardWidth / panels.GridColumns
+}
@@ -0,0 +1,127 @@
+package interpolate
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Formats of the values of a variable, as in ${var:format}.
+// Refers to https://grafana.com/docs/grafana/latest/variables/advanced-variable-format-options/
+const (
+	// a,b
+	FormatCSV = "csv"
+	// "a","b"
+	FormatDoubleQuote = "doublequote"
+	// {a,b}
+	FormatGlob = "glob"
+	// ["a","b"]
+	FormatJSON = "json"
+	// ("a" OR "b")
+	FormatLucene = "lucene"
+	// a%2Fb
+	FormatPercentEncode = "percentencode"
+	// a|b
+	FormatPipe = "pipe"
+	// a,b without escaping
+	FormatRaw = "raw"
+	// (a|b) with the regular expression characters escaped
+	FormatRegex = "regex"
+	// 'a','b'
+	FormatSingleQuote = "singlequote"
+	// 'a','b' with the quotes doubled
+	FormatSQLString = "sqlstring"
+	// a + b
+	FormatText = "text"
+)
+
+// the default format of expressions, which depends on the variable
+const formatPrometheus = "prometheus"
+
+// a formatter formats the values of a variable, which may hold several values when multiValued
+type formatter func(values []string, multiValued bool) string
+
+var formatters = map[string]formatter{
+	FormatCSV:           join(",", nil),
+	FormatDoubleQuote:   join(",", func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"` }),
+	FormatGlob:          wrap("{", ",", "}", nil),
+	FormatJSON:          formatJSON,
+	FormatLucene:        wrap("(", " OR ", ")", func(s string) string { return `"` + luceneEscape(s) + `"` }),
+	FormatPercentEncode: join(",", url.QueryEscape),
+	FormatPipe:          join("|", nil),
+	FormatRaw:           join(",", nil),
+	FormatRegex:         wrap("(", "|", ")", regexp.QuoteMeta),
+	FormatSingleQuote:   join(",", func(s string) string { return `'` + strings.ReplaceAll(s, `'`, `\'`) + `'` }),
+	FormatSQLString:     join(",", func(s string) string { return `'` + strings.ReplaceAll(s, `'`, `''`) + `'` }),
+	FormatText:          join(" + ", nil),
+	formatPrometheus:    formatPrometheusValues,
+}
+
+// join escapes the values with escape, if any, and joins them with sep
+func join(sep string, escape func(string) string) formatter {
+	return func(values []string, _ bool) string {
+		return strings.Join(escapeAll(values, escape), sep)
+	}
+}
+
+// wrap is like join, but wraps several values in prefix and suffix
+func wrap(prefix, sep, suffix string, escape func(string) string) formatter {
+	return func(values []string, _ bool) string {
+		escaped := escapeAll(values, escape)
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return prefix + strings.Join(escaped, sep) + suffix
+	}
+}
+
+func escapeAll(values []string, escape func(string) string) []string {
+	if escape == nil {
+		return values
+	}
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escape(value)
+	}
+	return escaped
+}
+
+func formatJSON(values []string, _ bool) string {
+	b, _ := json.Marshal(values)
+	return string(b)
+}
+
+var luceneSpecialChars = regexp.MustCompile(`[+\-&|!(){}\[\]^"~*?:\\/]`)
+
+func luceneEscape(s string) string {
+	return luceneSpecialChars.ReplaceAllString(s, `\$0`)
+}
+
+// PromQL string literals take backslash escapes, the regular expressions in them need the
+// backslash escaping the regular expression characters to be escaped too
+var (
+	promStringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`)
+	promRegexChars    = regexp.MustCompile(`[$^*{}\[\]'+?.()|]`)
+)
+
+// formatPrometheusValues escapes the value of a single-valued variable for a string literal, the values
+// of a multi-valued one for a regular expression inside a string literal, as they are matched with =~
+func formatPrometheusValues(values []string, multiValued bool) string {
+	if !multiValued {
+		return strings.Join(escapeAll(values, promStringEscaper.Replace), ",")
+	}
+	return wrap("(", "|", ")", func(s string) string {
+		s = strings.ReplaceAll(s, `\`, `\\\\`)
+		s = promRegexChars.ReplaceAllString(s, `\\$0`)
+		return strings.ReplaceAll(s, `"`, `\"`)
+	})(values, multiValued)
+}
+
+// legacyFormat maps the allFormat and multiFormat of query variables to a format
+func legacyFormat(format string) string {
+	if format == "regex values" {
+		return FormatRegex
+	}
+	return format
+}
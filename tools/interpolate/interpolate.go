@@ -0,0 +1,313 @@
+// Package interpolate expands the templating variables of a dashboard in the
+// expressions, legend formats and titles of its panels, and in the URLs of its links,
+// and repeats the panels and rows repeated by a variable.
+//
+// Variables are written as $var, ${var}, [[var]], ${var:format} or [[var:format]].
+// References to variables which are not defined, or have no value, are left as is.
+// The built-in variables, such as $__interval and $__range, are computed for every query.
+package interpolate
+
+import (
+	"regexp"
+	gotime "time"
+
+	"github.com/prometheus/common/model"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+)
+
+// AllText is the text of the all option of a variable
+const AllText = "All"
+
+// variablePattern matches $var, ${var}, ${var:format}, [[var]] and [[var:format]]
+var variablePattern = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?::([^}]*))?\}|\[\[(\w+)(?::([^\]]*))?\]\]`)
+
+// Selection is the current value of a variable
+type Selection struct {
+	// Selected values, a single one unless the variable is multi-valued
+	Values []string
+	// The all option is selected. Values then hold all the values of the variable,
+	// they may be left out for custom variables which know their options.
+	All bool
+}
+
+// Query is a target of a panel with its variables expanded
+type Query struct {
+	// ID of the panel
+	PanelID int64
+	// Title of the panel
+	PanelTitle string
+	// Reference ID of the target
+	RefID int64
+	// Datasource the query is sent to, the default one when nil
+	Datasource *datasources.DatasourceRef
+	// PromQL expression
+	Expression string
+	// Legend format of the series
+	LegendFormat string
+	// Evaluate the expression at To only
+	Instant bool
+	// Time range of the query, the one of the panel unless the target moves it back
+	From, To gotime.Time
+	// How far back the target moves the time range of its panel, the series are to be moved forward
+	// by as much to be compared with the other targets
+	TimeShift gotime.Duration
+	// Resolution of the query, the value of $__interval times the interval factor of the target
+	Interval gotime.Duration
+}
+
+// QueryOptions tell how the queries of a dashboard are resolved
+type QueryOptions struct {
+	// Selected values by variable name
+	Selected map[string]Selection
+	// Scrape interval of the datasource, DefaultScrapeInterval when zero
+	ScrapeInterval gotime.Duration
+	// Time the time range of the dashboard is resolved at, the current time when zero
+	Now gotime.Time
+}
+
+// Interpolator expands the variables of a dashboard with their selected values
+type Interpolator struct {
+	variables map[string]variable
+	builtins  map[string]string
+}
+
+// a variable with its value
+type variable struct {
+	templatings.TemplateVar
+	Selection
+}
+
+// New returns an Interpolator for the variables, with the given selection by variable name.
+// Variables without a selection take their default value: the selected options of a custom
+// variable, the value of a constant, the default of a textbox, the first interval.
+func New(variables []templatings.TemplateVar, selected map[string]Selection) *Interpolator {
+	in := &Interpolator{variables: make(map[string]variable, len(variables))}
+	for _, v := range variables {
+		selection, ok := selected[v.Name]
+		if !ok {
+			selection = defaultSelection(v)
+		}
+		if v.Type == templatings.TypeConstant && v.ConstantVariable != nil {
+			// constants are hidden and can't be selected
+			selection = Selection{Values: []string{v.ConstantVariable.Value}}
+		}
+		if selection.All && len(selection.Values) == 0 && v.CustomVariable != nil {
+			for _, option := range v.Options {
+				selection.Values = append(selection.Values, option.Value)
+			}
+		}
+		in.variables[v.Name] = variable{TemplateVar: v, Selection: selection}
+	}
+	return in
+}
+
+// WithBuiltins returns a copy of the Interpolator expanding the built-in variables too
+func (in *Interpolator) WithBuiltins(builtins Builtins) *Interpolator {
+	return &Interpolator{variables: in.variables, builtins: builtins.values()}
+}
+
+// Queries expands the variables in the targets and titles of the dashboard panels, including the
+// panels of rows. Hidden targets are left out. Queries span the time range of the dashboard, unless
+// their panel has its own or their target moves it back. The built-in variables are computed from
+// that time range, the width of the panels, the interval or step of the targets and the
+// scrape interval of the datasource. Queries are sent to the datasource of their target, or of
+// their panel, once the inputs and datasource variables are resolved. The panels and rows repeated
+// by a variable are expanded first, see Repeat.
+func Queries(spec *v1alpha2.DashboardSpec, opts QueryOptions) []Query {
+	spec = spec.DeepCopy()
+	spec.ResolveDatasources()
+	in := New(spec.Templatings, opts.Selected)
+
+	now := opts.Now
+	if now.IsZero() {
+		now = gotime.Now()
+	}
+
+	var queries []Query
+	var walk func(pls []*panels.Panel)
+	walk = func(pls []*panels.Panel) {
+		for _, panel := range pls {
+			if panel == nil {
+				continue
+			}
+			title := in.Text(panel.Title)
+			panelFrom, panelTo := PanelTimeRange(spec.Time, spec.Timezone, in.Text(panel.TimeFrom), in.Text(panel.TimeShift), now)
+			for _, target := range panel.Targets {
+				if target.Hide {
+					continue
+				}
+				from, to := ShiftTimeRange(panelFrom, panelTo, in.Text(target.TimeShift))
+				step := target.Interval
+				if step == "" {
+					step = target.Step
+				}
+				builtins := NewBuiltins(BuiltinOptions{
+					Range:          to.Sub(from),
+					MaxDataPoints:  PanelWidth(panel.GridPos),
+					MinInterval:    minInterval(in.Expression(step)),
+					ScrapeInterval: opts.ScrapeInterval,
+					IntervalFactor: target.IntervalFactor,
+				})
+				queries = append(queries, Query{
+					PanelID:      panel.Id,
+					PanelTitle:   title,
+					RefID:        target.RefID,
+					Datasource:   in.datasource(panel.Datasource, target.Datasource),
+					Expression:   in.WithBuiltins(builtins).Expression(target.Expression),
+					LegendFormat: in.Text(target.LegendFormat),
+					Instant:      !target.IsRange(),
+					From:         from,
+					To:           to,
+					TimeShift:    panelTo.Sub(to),
+					Interval:     builtins.Interval,
+				})
+			}
+			if panel.RowPanel != nil {
+				walk(panel.RowPanel.Panels)
+			}
+		}
+	}
+	walk(Repeat(spec, opts.Selected))
+	return queries
+}
+
+// datasource is the datasource of a target, the one of its panel unless it sets its own,
+// with the datasource variables replaced by the name of the selected datasource
+func (in *Interpolator) datasource(panel, target *datasources.DatasourceRef) *datasources.DatasourceRef {
+	ref := target
+	if ref == nil {
+		ref = panel
+	}
+	if ref.IsMixed() {
+		return nil
+	}
+	if ref.Variable() == "" {
+		return ref
+	}
+	resolved := datasources.FromName(in.Text(ref.String()))
+	if resolved != nil && resolved.Type == "" {
+		resolved.Type = ref.Type
+	}
+	return resolved
+}
+
+// Expression expands the variables of a PromQL expression. Unless a format is given,
+// single values are escaped for a string literal, and several values are escaped for a
+// regular expression and joined as (a|b), like grafana does for prometheus.
+func (in *Interpolator) Expression(expr string) string {
+	return in.Replace(expr, formatPrometheus)
+}
+
+// Text expands the variables of a text shown to the user, such as a title or a legend format.
+// Unless a format is given, several values are joined by " + ".
+func (in *Interpolator) Text(s string) string {
+	return in.Replace(s, FormatText)
+}
+
+// Replace expands the variables of s, formatting the values without a format with the given one
+func (in *Interpolator) Replace(s string, defaultFormat string) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		m := variablePattern.FindStringSubmatch(match)
+		name, format := m[1]+m[2]+m[4], m[3]+m[5]
+
+		if value, ok := in.builtins[name]; ok {
+			return value
+		}
+		v, ok := in.variables[name]
+		if !ok || len(v.Values) == 0 && !v.All {
+			return match
+		}
+		return v.format(format, defaultFormat)
+	})
+}
+
+// format formats the value of the variable. An explicit format comes first, then the legacy
+// allFormat and multiFormat of query variables, then the default format.
+func (v variable) format(format string, defaultFormat string) string {
+	if format == "" && v.QueryVariable != nil {
+		switch {
+		case v.All && v.allValue() != "":
+			// the custom all value comes first
+		case v.All && v.AllFormat == "wildcard":
+			return "*"
+		case v.All && v.AllFormat == "regex wildcard":
+			return ".*"
+		case v.All && v.AllFormat != "":
+			format = legacyFormat(v.AllFormat)
+		case len(v.Values) > 1 && v.MultiFormat != "":
+			format = legacyFormat(v.MultiFormat)
+		}
+	}
+	if _, ok := formatters[format]; !ok {
+		format = defaultFormat
+	}
+
+	if v.All {
+		if format == FormatText {
+			return AllText
+		}
+		// custom all values are used as is, they are usually regular expressions like .*
+		if allValue := v.allValue(); allValue != "" {
+			return allValue
+		}
+	}
+	return formatters[format](v.Values, v.multiValued())
+}
+
+// allValue returns the custom value of the all option
+func (v variable) allValue() string {
+	switch {
+	case v.QueryVariable != nil:
+		return v.QueryVariable.AllValue
+	case v.CustomVariable != nil:
+		return v.CustomVariable.AllValue
+	case v.DatasourceVariable != nil:
+		return v.DatasourceVariable.AllValue
+	}
+	return ""
+}
+
+// multiValued reports whether the variable may hold several values
+func (v variable) multiValued() bool {
+	var multi templatings.MultiValue
+	switch {
+	case v.QueryVariable != nil:
+		multi = v.QueryVariable.MultiValue
+	case v.CustomVariable != nil:
+		multi = v.CustomVariable.MultiValue
+	case v.DatasourceVariable != nil:
+		multi = v.DatasourceVariable.MultiValue
+	}
+	return multi.Multi || multi.IncludeAll
+}
+
+// minInterval parses the step of a target, such as 30s, zero when it has none
+func minInterval(step string) gotime.Duration {
+	d, err := model.ParseDuration(step)
+	if err != nil {
+		return 0
+	}
+	return gotime.Duration(d)
+}
+
+// defaultSelection returns the value of a variable nothing was selected for
+func defaultSelection(v templatings.TemplateVar) Selection {
+	switch {
+	case v.CustomVariable != nil:
+		var selection Selection
+		for _, option := range v.Options {
+			if option.Selected {
+				selection.Values = append(selection.Values, option.Value)
+			}
+		}
+		return selection
+	case v.TextboxVariable != nil && v.Default != "":
+		return Selection{Values: []string{v.Default}}
+	case v.IntervalVariable != nil && len(v.Intervals) > 0:
+		return Selection{Values: []string{v.Intervals[0]}}
+	}
+	return Selection{}
+}
@@ -0,0 +1,527 @@
+package interpolate
+
+import (
+	"encoding/json"
+	"testing"
+	gotime "time"
+
+	"github.com/stretchr/testify/require"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/datasources"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+)
+
+func variables(t *testing.T, js string) []templatings.TemplateVar {
+	var vars []templatings.TemplateVar
+	require.NoError(t, json.Unmarshal([]byte(js), &vars))
+	return vars
+}
+
+func TestExpression(t *testing.T) {
+	vars := variables(t, `[
+		{"name": "namespace", "type": "query", "query": "label_values(namespace)"},
+		{"name": "pod", "type": "query", "query": "label_values(pod)", "multi": true},
+		{"name": "node", "type": "query", "query": "label_values(node)", "multi": true, "includeAll": true},
+		{"name": "job", "type": "query", "query": "label_values(job)", "includeAll": true, "allValue": ".+"},
+		{"name": "instance", "type": "query", "query": "label_values(instance)", "multi": true, "multiFormat": "pipe"},
+		{"name": "device", "type": "query", "query": "label_values(device)", "includeAll": true, "allFormat": "regex wildcard"},
+		{"name": "env", "type": "custom", "options": [{"value": "prod"}, {"value": "staging"}], "includeAll": true},
+		{"name": "cluster", "type": "constant", "value": "host"},
+		{"name": "step", "type": "interval", "intervals": ["1m", "5m"]},
+		{"name": "filter", "type": "textbox", "default": "web"}
+	]`)
+
+	tests := []struct {
+		name     string
+		selected map[string]Selection
+		expr     string
+		want     string
+	}{
+		{
+			name:     "single value",
+			selected: map[string]Selection{"namespace": {Values: []string{"kube-system"}}},
+			expr:     `up{namespace="$namespace"}`,
+			want:     `up{namespace="kube-system"}`,
+		},
+		{
+			name:     "all syntaxes",
+			selected: map[string]Selection{"namespace": {Values: []string{"default"}}},
+			expr:     `$namespace ${namespace} [[namespace]]`,
+			want:     `default default default`,
+		},
+		{
+			name:     "single value escaped for a string literal",
+			selected: map[string]Selection{"namespace": {Values: []string{`a"b\c`}}},
+			expr:     `up{namespace="$namespace"}`,
+			want:     `up{namespace="a\"b\\c"}`,
+		},
+		{
+			name:     "multi value",
+			selected: map[string]Selection{"pod": {Values: []string{"web-1", "web-2"}}},
+			expr:     `up{pod=~"$pod"}`,
+			want:     `up{pod=~"(web-1|web-2)"}`,
+		},
+		{
+			name:     "multi value with a single value selected",
+			selected: map[string]Selection{"pod": {Values: []string{"web-1"}}},
+			expr:     `up{pod=~"$pod"}`,
+			want:     `up{pod=~"web-1"}`,
+		},
+		{
+			name:     "multi value escaped for a regular expression",
+			selected: map[string]Selection{"pod": {Values: []string{"a.b", `c\d`}}},
+			expr:     `up{pod=~"$pod"}`,
+			want:     `up{pod=~"(a\\.b|c\\\\d)"}`,
+		},
+		{
+			name:     "all values",
+			selected: map[string]Selection{"node": {All: true, Values: []string{"n1", "n2", "n3"}}},
+			expr:     `up{node=~"$node"}`,
+			want:     `up{node=~"(n1|n2|n3)"}`,
+		},
+		{
+			name:     "custom all value",
+			selected: map[string]Selection{"job": {All: true, Values: []string{"a", "b"}}},
+			expr:     `up{job=~"$job"}`,
+			want:     `up{job=~".+"}`,
+		},
+		{
+			name:     "custom all value with a format",
+			selected: map[string]Selection{"job": {All: true}},
+			expr:     `up{job=~"${job:regex}"}`,
+			want:     `up{job=~".+"}`,
+		},
+		{
+			name:     "all of a custom variable",
+			selected: map[string]Selection{"env": {All: true}},
+			expr:     `up{env=~"$env"}`,
+			want:     `up{env=~"(prod|staging)"}`,
+		},
+		{
+			name:     "multi format",
+			selected: map[string]Selection{"instance": {Values: []string{"a:9100", "b:9100"}}},
+			expr:     `up{instance=~"$instance"}`,
+			want:     `up{instance=~"a:9100|b:9100"}`,
+		},
+		{
+			name:     "explicit format over multi format",
+			selected: map[string]Selection{"instance": {Values: []string{"a:9100", "b:9100"}}},
+			expr:     `up{instance=~"${instance:csv}"}`,
+			want:     `up{instance=~"a:9100,b:9100"}`,
+		},
+		{
+			name:     "all format",
+			selected: map[string]Selection{"device": {All: true, Values: []string{"sda", "sdb"}}},
+			expr:     `node_disk_io_time_seconds_total{device=~"$device"}`,
+			want:     `node_disk_io_time_seconds_total{device=~".*"}`,
+		},
+		{
+			name:     "all format ignored for a single value",
+			selected: map[string]Selection{"device": {Values: []string{"sda"}}},
+			expr:     `node_disk_io_time_seconds_total{device=~"$device"}`,
+			want:     `node_disk_io_time_seconds_total{device=~"sda"}`,
+		},
+		{
+			name: "constant",
+			expr: `up{cluster="$cluster"}`,
+			want: `up{cluster="host"}`,
+		},
+		{
+			name:     "constant can't be selected",
+			selected: map[string]Selection{"cluster": {Values: []string{"member"}}},
+			expr:     `up{cluster="$cluster"}`,
+			want:     `up{cluster="host"}`,
+		},
+		{
+			name: "first interval by default",
+			expr: `rate(http_requests_total[$step])`,
+			want: `rate(http_requests_total[1m])`,
+		},
+		{
+			name:     "selected interval",
+			selected: map[string]Selection{"step": {Values: []string{"5m"}}},
+			expr:     `rate(http_requests_total[$step])`,
+			want:     `rate(http_requests_total[5m])`,
+		},
+		{
+			name: "textbox default",
+			expr: `up{job=~"$filter.*"}`,
+			want: `up{job=~"web.*"}`,
+		},
+		{
+			name: "no selection",
+			expr: `up{namespace="$namespace"}`,
+			want: `up{namespace="$namespace"}`,
+		},
+		{
+			name: "unknown variable",
+			expr: `up{namespace="$unknown", pod="${other:regex}", node="[[node_name]]"}`,
+			want: `up{namespace="$unknown", pod="${other:regex}", node="[[node_name]]"}`,
+		},
+		{
+			name:     "unknown format",
+			selected: map[string]Selection{"pod": {Values: []string{"a", "b"}}},
+			expr:     `up{pod=~"${pod:nope}"}`,
+			want:     `up{pod=~"(a|b)"}`,
+		},
+		{
+			name:     "name is not a prefix",
+			selected: map[string]Selection{"pod": {Values: []string{"a"}}},
+			expr:     `up{pod="$pods"}`,
+			want:     `up{pod="$pods"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, New(vars, test.selected).Expression(test.expr))
+		})
+	}
+}
+
+func TestFormats(t *testing.T) {
+	vars := variables(t, `[{"name": "pod", "type": "query", "query": "label_values(pod)", "multi": true}]`)
+
+	tests := []struct {
+		format string
+		values []string
+		want   string
+	}{
+		{FormatCSV, []string{"a", "b"}, "a,b"},
+		{FormatDoubleQuote, []string{"a", `b"`}, `"a","b\""`},
+		{FormatGlob, []string{"a", "b"}, "{a,b}"},
+		{FormatGlob, []string{"a"}, "a"},
+		{FormatJSON, []string{"a", "b"}, `["a","b"]`},
+		{FormatLucene, []string{"a", "b:c"}, `("a" OR "b\:c")`},
+		{FormatPercentEncode, []string{"a/b", "c d"}, "a%2Fb,c+d"},
+		{FormatPipe, []string{"a", "b"}, "a|b"},
+		{FormatRaw, []string{"a.b", "c"}, "a.b,c"},
+		{FormatRegex, []string{"a.b", "c"}, `(a\.b|c)`},
+		{FormatRegex, []string{"a.b"}, `a\.b`},
+		{FormatSingleQuote, []string{"a", "b'c"}, `'a','b\'c'`},
+		{FormatSQLString, []string{"a", "b'c"}, `'a','b''c'`},
+		{FormatText, []string{"a", "b"}, "a + b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			in := New(vars, map[string]Selection{"pod": {Values: test.values}})
+			require.Equal(t, test.want, in.Expression("${pod:"+test.format+"}"))
+			require.Equal(t, test.want, in.Expression("[[pod:"+test.format+"]]"))
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	vars := variables(t, `[
+		{"name": "namespace", "type": "query", "query": "label_values(namespace)"},
+		{"name": "pod", "type": "query", "query": "label_values(pod)", "multi": true, "includeAll": true},
+		{"name": "job", "type": "query", "query": "label_values(job)", "includeAll": true, "allValue": ".+"}
+	]`)
+
+	tests := []struct {
+		name     string
+		selected map[string]Selection
+		text     string
+		want     string
+	}{
+		{
+			name:     "single value",
+			selected: map[string]Selection{"namespace": {Values: []string{"a.b"}}},
+			text:     "Pods in $namespace",
+			want:     "Pods in a.b",
+		},
+		{
+			name:     "multi value",
+			selected: map[string]Selection{"pod": {Values: []string{"web-1", "web-2"}}},
+			text:     "CPU of $pod",
+			want:     "CPU of web-1 + web-2",
+		},
+		{
+			name:     "all values",
+			selected: map[string]Selection{"pod": {All: true, Values: []string{"web-1", "web-2"}}},
+			text:     "CPU of $pod",
+			want:     "CPU of All",
+		},
+		{
+			name:     "custom all value",
+			selected: map[string]Selection{"job": {All: true}},
+			text:     "Jobs: [[job]]",
+			want:     "Jobs: All",
+		},
+		{
+			name:     "explicit format",
+			selected: map[string]Selection{"pod": {Values: []string{"web-1", "web-2"}}},
+			text:     "CPU of ${pod:csv}",
+			want:     "CPU of web-1,web-2",
+		},
+		{
+			name:     "label references are left to the datasource",
+			selected: map[string]Selection{"namespace": {Values: []string{"default"}}},
+			text:     "{{pod}} in $namespace",
+			want:     "{{pod}} in default",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, New(vars, test.selected).Text(test.text))
+		})
+	}
+}
+
+func TestQueries(t *testing.T) {
+	req := require.New(t)
+
+	var spec v1alpha2.DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"templatings": [
+			{"name": "namespace", "type": "query", "query": "label_values(namespace)"},
+			{"name": "pod", "type": "query", "query": "label_values(pod)", "multi": true}
+		],
+		"panels": [
+			{"id": 1, "type": "graph", "title": "CPU in $namespace", "targets": [
+				{"refId": 1, "expr": "sum(rate(container_cpu_usage_seconds_total{namespace=\"$namespace\", pod=~\"$pod\"}[5m])) by (pod)", "legendFormat": "{{pod}}"},
+				{"refId": 2, "expr": "sum(kube_pod_container_resource_limits{namespace=\"$namespace\"})", "legendFormat": "limit of $namespace"}
+			]},
+			{"id": 2, "type": "row", "title": "Memory", "panels": [
+				{"id": 3, "type": "graph", "title": "Memory of $pod", "targets": [
+					{"refId": 1, "expr": "sum(container_memory_working_set_bytes{pod=~\"$pod\"}) by (pod)"}
+				]}
+			]},
+			null
+		]
+	}`), &spec))
+
+	now := gotime.Date(2021, gotime.June, 1, 12, 0, 0, 0, gotime.UTC)
+	from, to := now.Add(-DefaultRange), now
+	queries := Queries(&spec, QueryOptions{
+		Selected: map[string]Selection{
+			"namespace": {Values: []string{"default"}},
+			"pod":       {Values: []string{"web-1", "web-2"}},
+		},
+		Now: now,
+	})
+	req.Equal([]Query{
+		{
+			PanelID:      1,
+			PanelTitle:   "CPU in default",
+			RefID:        1,
+			Expression:   `sum(rate(container_cpu_usage_seconds_total{namespace="default", pod=~"(web-1|web-2)"}[5m])) by (pod)`,
+			LegendFormat: "{{pod}}",
+			From:         from,
+			To:           to,
+			Interval:     15 * gotime.Second,
+		},
+		{
+			PanelID:      1,
+			PanelTitle:   "CPU in default",
+			RefID:        2,
+			Expression:   `sum(kube_pod_container_resource_limits{namespace="default"})`,
+			LegendFormat: "limit of default",
+			From:         from,
+			To:           to,
+			Interval:     15 * gotime.Second,
+		},
+		{
+			PanelID:    3,
+			PanelTitle: "Memory of web-1 + web-2",
+			RefID:      1,
+			Expression: `sum(container_memory_working_set_bytes{pod=~"(web-1|web-2)"}) by (pod)`,
+			From:       from,
+			To:         to,
+			Interval:   15 * gotime.Second,
+		},
+	}, queries)
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		name string
+		opts BuiltinOptions
+		want Builtins
+	}{
+		{
+			name: "scrape interval as lower bound",
+			opts: BuiltinOptions{Range: gotime.Hour, MaxDataPoints: 1920},
+			want: Builtins{Interval: 15 * gotime.Second, Range: gotime.Hour, RateInterval: gotime.Minute},
+		},
+		{
+			name: "range divided in data points",
+			opts: BuiltinOptions{Range: 24 * gotime.Hour, MaxDataPoints: 960},
+			want: Builtins{Interval: gotime.Minute, Range: 24 * gotime.Hour, RateInterval: 75 * gotime.Second},
+		},
+		{
+			name: "rounded interval",
+			opts: BuiltinOptions{Range: 7 * 24 * gotime.Hour, MaxDataPoints: 480},
+			want: Builtins{Interval: 20 * gotime.Minute, Range: 7 * 24 * gotime.Hour, RateInterval: 20*gotime.Minute + 15*gotime.Second},
+		},
+		{
+			name: "min interval",
+			opts: BuiltinOptions{Range: gotime.Hour, MaxDataPoints: 1920, MinInterval: gotime.Minute},
+			want: Builtins{Interval: gotime.Minute, Range: gotime.Hour, RateInterval: 75 * gotime.Second},
+		},
+		{
+			name: "scrape interval",
+			opts: BuiltinOptions{Range: gotime.Hour, MaxDataPoints: 1920, ScrapeInterval: 30 * gotime.Second},
+			want: Builtins{Interval: 30 * gotime.Second, Range: gotime.Hour, RateInterval: 2 * gotime.Minute},
+		},
+		{
+			name: "interval factor",
+			opts: BuiltinOptions{Range: 24 * gotime.Hour, MaxDataPoints: 960, IntervalFactor: 2},
+			want: Builtins{Interval: 2 * gotime.Minute, Range: 24 * gotime.Hour, RateInterval: 135 * gotime.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, NewBuiltins(test.opts))
+		})
+	}
+}
+
+func TestBuiltinVariables(t *testing.T) {
+	req := require.New(t)
+
+	in := New(nil, nil).WithBuiltins(Builtins{
+		Interval:     30 * gotime.Second,
+		Range:        gotime.Hour,
+		RateInterval: 2 * gotime.Minute,
+	})
+	req.Equal(`rate(up[30s]) rate(up[2m]) increase(up[3600s]) 3600 3600000 30000`,
+		in.Expression(`rate(up[$__interval]) rate(up[${__rate_interval}]) increase(up[[[__range]]]) $__range_s $__range_ms $__interval_ms`))
+	req.Equal(`$__interval`, New(nil, nil).Expression(`$__interval`))
+}
+
+func TestTimeRange(t *testing.T) {
+	now := gotime.Date(2021, gotime.March, 31, 12, 0, 0, 0, gotime.UTC)
+
+	tests := []struct {
+		from, to string
+		timezone string
+		want     gotime.Duration
+	}{
+		{"now-1h", "now", "", gotime.Hour},
+		{"now-7d", "", "utc", 7 * 24 * gotime.Hour},
+		{"now-2d", "now-1d", "utc", 24 * gotime.Hour},
+		{"now-1M", "now", "utc", 31 * 24 * gotime.Hour},
+		{"now-1y", "now", "utc", 365 * 24 * gotime.Hour},
+		{"now/d", "now/d", "utc", 24*gotime.Hour - gotime.Millisecond},
+		{"now/d", "now", "Asia/Shanghai", 20 * gotime.Hour},
+		{"", "", "utc", DefaultRange},
+		{"now", "now-1h", "utc", DefaultRange},
+		{"yesterday", "now", "utc", DefaultRange},
+		{"now-1h", "now", "Mars/Olympus", DefaultRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.from+" to "+test.to+" in "+test.timezone, func(t *testing.T) {
+			from, to := TimeRange(time.Time{From: test.from, To: test.to}, test.timezone, now)
+			require.Equal(t, test.want, to.Sub(from))
+		})
+	}
+}
+
+func TestQueriesBuiltins(t *testing.T) {
+	req := require.New(t)
+
+	var spec v1alpha2.DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"time": {"from": "now-24h", "to": "now"},
+		"templatings": [{"name": "step", "type": "interval", "intervals": ["5m"]}],
+		"panels": [
+			{"id": 1, "type": "graph", "gridPos": {"w": 12, "h": 8}, "targets": [
+				{"refId": 1, "expr": "sum(rate(http_requests_total[$__rate_interval]))"},
+				{"refId": 2, "expr": "sum(rate(http_requests_total[$__interval]))", "interval": "$step"},
+				{"refId": 3, "expr": "sum(rate(http_requests_total[$__interval]))", "intervalFactor": 2},
+				{"refId": 4, "expr": "sum(http_requests_total)", "hide": true}
+			]},
+			{"id": 2, "type": "stat", "targets": [
+				{"refId": 1, "expr": "sum(increase(http_requests_total[$__range]))", "instant": true}
+			]}
+		]
+	}`), &spec))
+
+	queries := Queries(&spec, QueryOptions{ScrapeInterval: 30 * gotime.Second})
+	req.Len(queries, 4)
+	req.Equal("sum(rate(http_requests_total[2m]))", queries[0].Expression)
+	req.Equal(gotime.Minute, queries[0].Interval)
+	req.False(queries[0].Instant)
+	req.Equal("sum(rate(http_requests_total[5m]))", queries[1].Expression)
+	req.Equal(5*gotime.Minute, queries[1].Interval)
+	req.Equal("sum(rate(http_requests_total[2m]))", queries[2].Expression)
+	req.Equal(2*gotime.Minute, queries[2].Interval)
+	req.Equal("sum(increase(http_requests_total[86400s]))", queries[3].Expression)
+	req.Equal(30*gotime.Second, queries[3].Interval)
+	req.True(queries[3].Instant)
+}
+
+func TestQueriesTimeOverrides(t *testing.T) {
+	req := require.New(t)
+
+	var spec v1alpha2.DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"time": {"from": "now-6h", "to": "now"},
+		"timezone": "utc",
+		"templatings": [{"name": "shift", "type": "custom", "options": [{"value": "1w", "selected": true}]}],
+		"panels": [
+			{"id": 1, "type": "graph", "targets": [
+				{"refId": 1, "expr": "sum(rate(http_requests_total[$__range]))"},
+				{"refId": 2, "expr": "sum(rate(http_requests_total[$__range]))", "timeShift": "$shift"}
+			]},
+			{"id": 2, "type": "stat", "timeFrom": "24h", "timeShift": "1d", "targets": [
+				{"refId": 1, "expr": "sum(increase(http_requests_total[$__range]))", "instant": true}
+			]}
+		]
+	}`), &spec))
+
+	now := gotime.Date(2021, gotime.June, 8, 12, 0, 0, 0, gotime.UTC)
+	queries := Queries(&spec, QueryOptions{Now: now})
+	req.Len(queries, 3)
+
+	req.Equal(now.Add(-6*gotime.Hour), queries[0].From)
+	req.Equal(now, queries[0].To)
+	req.Zero(queries[0].TimeShift)
+
+	lastWeek := now.AddDate(0, 0, -7)
+	req.Equal(lastWeek.Add(-6*gotime.Hour), queries[1].From)
+	req.Equal(lastWeek, queries[1].To)
+	req.Equal(7*24*gotime.Hour, queries[1].TimeShift)
+	req.Equal("sum(rate(http_requests_total[21600s]))", queries[1].Expression)
+
+	yesterday := now.AddDate(0, 0, -1)
+	req.Equal(yesterday.Add(-24*gotime.Hour), queries[2].From)
+	req.Equal(yesterday, queries[2].To)
+	req.Zero(queries[2].TimeShift)
+	req.Equal("sum(increase(http_requests_total[86400s]))", queries[2].Expression)
+}
+
+func TestQueriesDatasources(t *testing.T) {
+	req := require.New(t)
+
+	var spec v1alpha2.DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"inputs": [{"name": "DS_PROMETHEUS", "pluginId": "prometheus", "datasource": {"uid": "prom"}}],
+		"templatings": [{"name": "ds", "type": "datasource", "pluginId": "prometheus"}],
+		"panels": [
+			{"id": 1, "type": "graph", "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}, "targets": [
+				{"refId": 1, "expr": "up"}
+			]},
+			{"id": 2, "type": "graph", "datasource": "-- Mixed --", "targets": [
+				{"refId": 1, "expr": "up", "datasource": "$ds"},
+				{"refId": 2, "expr": "up", "datasource": {"type": "prometheus", "uid": "thanos"}},
+				{"refId": 3, "expr": "up"}
+			]}
+		]
+	}`), &spec))
+
+	queries := Queries(&spec, QueryOptions{Selected: map[string]Selection{"ds": {Values: []string{"Prometheus"}}}})
+	req.Len(queries, 4)
+	req.Equal(&datasources.DatasourceRef{Type: "prometheus", UID: "prom"}, queries[0].Datasource)
+	req.Equal(&datasources.DatasourceRef{Name: "Prometheus"}, queries[1].Datasource)
+	req.Equal(&datasources.DatasourceRef{Type: "prometheus", UID: "thanos"}, queries[2].Datasource)
+	req.Nil(queries[3].Datasource)
+	// the spec itself is left as is
+	req.Equal("${DS_PROMETHEUS}", spec.Panels[0].Datasource.UID)
+}
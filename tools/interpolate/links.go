@@ -0,0 +1,106 @@
+package interpolate
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/templatings"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+)
+
+// Built-in variables of link URLs
+const (
+	// Time range of the dashboard as URL parameters, such as from=now-1h&to=now
+	VarURLTimeRange = "__url_time_range"
+	// Values of the variables as URL parameters, such as var-namespace=default&var-pod=web-1&var-pod=web-2
+	VarAllVariables = "__all_variables"
+)
+
+// AllParamValue is the URL parameter of a variable with the all option selected, as grafana writes it
+const AllParamValue = "$__all"
+
+// LinkURL expands the variables of the URL and parameters of a link, percent encoding their values,
+// and passes the values of the variables and the time range t of the dashboard when the link keeps them.
+// The links to dashboards get the query string to open the dashboards with.
+func (in *Interpolator) LinkURL(link links.Link, t time.Time) string {
+	builtins := map[string]string{
+		VarURLTimeRange: timeRangeParams(t),
+		VarAllVariables: in.variableParams(),
+	}
+	for name, value := range in.builtins {
+		builtins[name] = value
+	}
+	li := &Interpolator{variables: in.variables, builtins: builtins}
+
+	var params []string
+	if link.IncludeVars {
+		params = append(params, builtins[VarAllVariables])
+	}
+	if link.KeepTime {
+		params = append(params, builtins[VarURLTimeRange])
+	}
+	params = append(params, li.Replace(link.Params, FormatPercentEncode))
+
+	var u string
+	if link.Type == links.TypeLink || link.Type == "" {
+		u = li.Replace(link.URL, FormatPercentEncode)
+	}
+	return withParams(u, params)
+}
+
+// withParams adds the query parameters to the URL
+func withParams(u string, params []string) string {
+	var query []string
+	for _, param := range params {
+		if param = strings.TrimLeft(param, "?&"); param != "" {
+			query = append(query, param)
+		}
+	}
+	if len(query) == 0 {
+		return u
+	}
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + strings.Join(query, "&")
+}
+
+// variableParams writes the values of the variables as URL parameters, leaving out the adhoc filters
+func (in *Interpolator) variableParams() string {
+	names := make([]string, 0, len(in.variables))
+	for name := range in.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []string
+	for _, name := range names {
+		v := in.variables[name]
+		if v.Type == templatings.TypeAdhoc {
+			continue
+		}
+		if v.All {
+			params = append(params, "var-"+url.QueryEscape(name)+"="+AllParamValue)
+			continue
+		}
+		for _, value := range v.Values {
+			params = append(params, "var-"+url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// timeRangeParams writes the time range as URL parameters, relative times such as now-1h kept as they are
+func timeRangeParams(t time.Time) string {
+	var params []string
+	if t.From != "" {
+		params = append(params, "from="+url.QueryEscape(t.From))
+	}
+	if t.To != "" {
+		params = append(params, "to="+url.QueryEscape(t.To))
+	}
+	return strings.Join(params, "&")
+}
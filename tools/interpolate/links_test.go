@@ -0,0 +1,69 @@
+package interpolate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/links"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/time"
+)
+
+func TestLinkURL(t *testing.T) {
+	vars := variables(t, `[
+		{"name": "namespace", "type": "query", "query": "label_values(namespace)"},
+		{"name": "pod", "type": "query", "query": "label_values(pod)", "multi": true, "includeAll": true},
+		{"name": "filters", "type": "adhoc"}
+	]`)
+	in := New(vars, map[string]Selection{
+		"namespace": {Values: []string{"kube system"}},
+		"pod":       {Values: []string{"web-1", "web-2"}},
+	})
+	all := New(vars, map[string]Selection{
+		"namespace": {Values: []string{"default"}},
+		"pod":       {All: true},
+	})
+	t1h := time.Time{From: "now-1h", To: "now"}
+
+	tests := []struct {
+		name string
+		in   *Interpolator
+		link links.Link
+		want string
+	}{
+		{
+			name: "url",
+			in:   in,
+			link: links.Link{URL: "https://example.com/logs?namespace=$namespace&pod=${pod}"},
+			want: "https://example.com/logs?namespace=kube+system&pod=web-1,web-2",
+		},
+		{
+			name: "built-in variables",
+			in:   in,
+			link: links.Link{Type: links.TypeLink, URL: "/d/pods?${__all_variables}&${__url_time_range}"},
+			want: "/d/pods?var-namespace=kube+system&var-pod=web-1&var-pod=web-2&from=now-1h&to=now",
+		},
+		{
+			name: "include variables and keep time",
+			in:   all,
+			link: links.Link{Type: links.TypeLink, URL: "/d/pods?orgId=1", IncludeVars: true, KeepTime: true},
+			want: "/d/pods?orgId=1&var-namespace=default&var-pod=$__all&from=now-1h&to=now",
+		},
+		{
+			name: "dashboard with params",
+			in:   in,
+			link: links.Link{Type: links.TypeDashboard, Dashboard: "pods", KeepTime: true, Params: "var-pod=${pod:raw}"},
+			want: "?from=now-1h&to=now&var-pod=web-1,web-2",
+		},
+		{
+			name: "dashboards",
+			in:   in,
+			link: links.Link{Type: links.TypeDashboards, Tags: []string{"kubernetes"}},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.in.LinkURL(tt.link, t1h))
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package interpolate
+
+import (
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// Repeat returns the panels of the dashboard with the panels and rows repeated by a variable replaced
+// by a copy for each of its values, the selected ones or the default ones like New. The value is
+// written in place of the variable in the titles, targets and links of the copy, and of the panels
+// of a copied row, and the copies are not repeated again.
+//
+// The first copy keeps the ID of the panel, the others are numbered after the highest panel ID of
+// the dashboard. Copies of a panel repeated horizontally share the width of the dashboard, at most
+// maxPerRow side by side; copies repeated vertically and copies of rows are stacked. The panels below
+// move down to make room.
+//
+// Panels repeated by a variable without values, such as a query variable with all selected and
+// no values listed, are kept as they are.
+func Repeat(spec *v1alpha2.DashboardSpec, selected map[string]Selection) []*panels.Panel {
+	r := &repeater{in: New(spec.Templatings, selected)}
+	for _, panel := range panels.Flatten(spec.Panels) {
+		if panel.Id > r.lastID {
+			r.lastID = panel.Id
+		}
+	}
+	pls, _ := r.repeat(spec.Panels, 0, false)
+	return pls
+}
+
+type repeater struct {
+	in *Interpolator
+	// highest ID given to a panel so far
+	lastID int64
+}
+
+// the panels starting at or below a row of the grid move down by a height
+type shift struct {
+	from, by int32
+}
+
+// repeat copies the panels, repeated or not, moving them down by offset and by the height added by the
+// copies above them. It returns the height added. With renumber set, every copy gets a new ID.
+func (r *repeater) repeat(pls []*panels.Panel, offset int32, renumber bool) ([]*panels.Panel, int32) {
+	var repeated []*panels.Panel
+	var shifts []shift
+	var added int32
+	for _, panel := range pls {
+		if panel == nil {
+			continue
+		}
+		dy := offset
+		if panel.GridPos != nil {
+			for _, s := range shifts {
+				if panel.GridPos.Y >= s.from {
+					dy += s.by
+				}
+			}
+		}
+
+		var copies []*panels.Panel
+		var extra int32
+		if panel.RowPanel != nil {
+			copies, extra = r.repeatRow(panel, dy, renumber)
+		} else {
+			copies, extra = r.repeatPanel(panel, dy, renumber)
+		}
+		repeated = append(repeated, copies...)
+
+		if extra > 0 && panel.GridPos != nil {
+			shifts = append(shifts, shift{from: panel.GridPos.Y + sectionHeight(panel), by: extra})
+			added += extra
+		}
+	}
+	return repeated, added
+}
+
+// repeatPanel lays out the copies of a panel which is not a row
+func (r *repeater) repeatPanel(panel *panels.Panel, dy int32, renumber bool) ([]*panels.Panel, int32) {
+	values := r.values(panel)
+	if len(values) == 0 {
+		c := r.copy(panel, "", "", renumber)
+		moveDown(c, dy)
+		return []*panels.Panel{c}, 0
+	}
+
+	perRow := int32(len(values))
+	if panel.RepeatDirection == panels.RepeatDirectionVertical {
+		perRow = 1
+	} else {
+		maxPerRow := panel.MaxPerRow
+		if maxPerRow <= 0 {
+			maxPerRow = panels.DefaultMaxPerRow
+		}
+		if perRow > maxPerRow {
+			perRow = maxPerRow
+		}
+		// like grafana, the copies are at least a column wide
+		if perRow > panels.GridColumns {
+			perRow = panels.GridColumns
+		}
+	}
+
+	var copies []*panels.Panel
+	for i, value := range values {
+		c := r.copy(panel, panel.Repeat, value, renumber || i > 0)
+		if pos := c.GridPos; pos != nil {
+			k := int32(i)
+			if perRow > 1 {
+				pos.W = panels.GridColumns / perRow
+				pos.X = k % perRow * pos.W
+			}
+			pos.Y += dy + k/perRow*pos.H
+		}
+		copies = append(copies, c)
+	}
+
+	if panel.GridPos == nil {
+		return copies, 0
+	}
+	lines := (int32(len(values)) + perRow - 1) / perRow
+	return copies, (lines - 1) * panel.GridPos.H
+}
+
+// repeatRow stacks the copies of a row with their panels, the panels being repeated in turn
+func (r *repeater) repeatRow(row *panels.Panel, dy int32, renumber bool) ([]*panels.Panel, int32) {
+	name, values := row.Repeat, r.values(row)
+	if len(values) == 0 {
+		// not repeated, a single copy of the row without a value
+		name, values = "", []string{""}
+	}
+
+	height := sectionHeight(row)
+	var copies []*panels.Panel
+	var cursor int32
+	for i, value := range values {
+		c := r.copy(row, name, value, renumber || i > 0)
+		moveDown(c, dy+cursor)
+
+		var added int32
+		c.RowPanel.Panels, added = r.repeat(c.RowPanel.Panels, dy+cursor, renumber || i > 0)
+		if c.RowPanel.Collapsed {
+			added = 0
+		}
+		copies = append(copies, c)
+		cursor += height + added
+	}
+	return copies, cursor - height
+}
+
+// values lists the values the panel is repeated for, none if it isn't repeated
+func (r *repeater) values(panel *panels.Panel) []string {
+	if panel.Repeat == "" {
+		return nil
+	}
+	return r.in.variables[panel.Repeat].Values
+}
+
+// copy copies the panel, with the value in place of the variable unless name is empty.
+// The panels of a row are copied as they are, to be repeated next.
+func (r *repeater) copy(panel *panels.Panel, name, value string, renumber bool) *panels.Panel {
+	c := panel.DeepCopy()
+	if renumber {
+		r.lastID++
+		c.Id = r.lastID
+	}
+	if name == "" {
+		return c
+	}
+
+	scoped := &Interpolator{variables: map[string]variable{name: {
+		TemplateVar: r.in.variables[name].TemplateVar,
+		Selection:   Selection{Values: []string{value}},
+	}}}
+	scoped.substitute(c)
+	c.Repeat = ""
+	return c
+}
+
+// substitute expands the variables in the titles, time overrides, targets, links and column links of the panel and of the panels of a row
+func (in *Interpolator) substitute(panel *panels.Panel) {
+	panel.Title = in.Text(panel.Title)
+	if panel.Description != nil {
+		description := in.Text(*panel.Description)
+		panel.Description = &description
+	}
+	if ref := panel.Datasource; ref != nil {
+		ref.UID, ref.Name = in.Text(ref.UID), in.Text(ref.Name)
+	}
+	panel.TimeFrom, panel.TimeShift = in.Text(panel.TimeFrom), in.Text(panel.TimeShift)
+	for i := range panel.Targets {
+		target := &panel.Targets[i]
+		target.Expression = in.Expression(target.Expression)
+		target.LegendFormat = in.Text(target.LegendFormat)
+		target.Interval = in.Text(target.Interval)
+		target.TimeShift = in.Text(target.TimeShift)
+		if ref := target.Datasource; ref != nil {
+			ref.UID, ref.Name = in.Text(ref.UID), in.Text(ref.Name)
+		}
+	}
+	for i := range panel.Links {
+		link := &panel.Links[i]
+		link.Title = in.Text(link.Title)
+		link.URL = in.Replace(link.URL, FormatPercentEncode)
+		link.Params = in.Replace(link.Params, FormatPercentEncode)
+		link.Dashboard = in.Text(link.Dashboard)
+	}
+	if panel.TablePanel != nil {
+		for _, style := range panel.TablePanel.Styles {
+			if style.Link != nil {
+				style.Link.URL = in.Replace(style.Link.URL, FormatPercentEncode)
+			}
+		}
+	}
+	if panel.RowPanel != nil {
+		for _, child := range panel.RowPanel.Panels {
+			if child != nil {
+				in.substitute(child)
+			}
+		}
+	}
+}
+
+// sectionHeight is the height of a panel, or the height of a row together with its panels unless it is collapsed
+func sectionHeight(panel *panels.Panel) int32 {
+	if panel.GridPos == nil {
+		return 0
+	}
+	height := panel.GridPos.H
+	if panel.RowPanel == nil || panel.RowPanel.Collapsed {
+		return height
+	}
+	for _, child := range panel.RowPanel.Panels {
+		if child != nil && child.GridPos != nil && child.GridPos.Y+child.GridPos.H-panel.GridPos.Y > height {
+			height = child.GridPos.Y + child.GridPos.H - panel.GridPos.Y
+		}
+	}
+	return height
+}
+
+// moveDown moves the panel, but not the panels of a row, down the grid
+func moveDown(panel *panels.Panel, dy int32) {
+	if panel.GridPos != nil {
+		panel.GridPos.Y += dy
+	}
+}
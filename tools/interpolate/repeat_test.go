@@ -0,0 +1,139 @@
+package interpolate
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2"
+	"kubesphere.io/monitoring-dashboard/api/v1alpha2/panels"
+)
+
+// layout lists the id, title and position of the panels, the panels of rows after their row
+func layout(pls []*panels.Panel) []string {
+	var positions []string
+	for _, panel := range panels.Flatten(pls) {
+		pos := panel.GridPos
+		positions = append(positions, fmt.Sprintf("%d %s %d,%d %dx%d", panel.Id, panel.Title, pos.X, pos.Y, pos.W, pos.H))
+	}
+	return positions
+}
+
+func TestRepeat(t *testing.T) {
+	req := require.New(t)
+
+	var spec v1alpha2.DashboardSpec
+	req.NoError(json.Unmarshal([]byte(`{
+		"templatings": [
+			{"name": "gpu", "type": "custom", "multi": true, "includeAll": true, "options": [
+				{"text": "0", "value": "0"}, {"text": "1", "value": "1"}, {"text": "2", "value": "2"}
+			]},
+			{"name": "node", "type": "query", "query": "label_values(node)", "multi": true}
+		],
+		"panels": [
+			{"id": 1, "type": "graph", "title": "GPU $gpu", "repeat": "gpu", "maxPerRow": 2,
+				"gridPos": {"x": 0, "y": 0, "w": 24, "h": 8},
+				"targets": [{"refId": 1, "expr": "DCGM_FI_DEV_GPU_TEMP{gpu=\"$gpu\"}", "legendFormat": "GPU $gpu"}],
+				"links": [{"url": "/d/gpu?var-gpu=$gpu"}]},
+			{"id": 2, "type": "graph", "title": "Power", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 8}},
+			{"id": 3, "type": "row", "title": "Node $node", "repeat": "node", "gridPos": {"x": 0, "y": 16, "w": 24, "h": 1}, "panels": [
+				{"id": 4, "type": "graph", "title": "Load of $node", "repeat": "gpu", "repeatDirection": "v",
+					"gridPos": {"x": 0, "y": 17, "w": 12, "h": 4}},
+				{"id": 5, "type": "graph", "title": "Memory of $node", "gridPos": {"x": 12, "y": 17, "w": 12, "h": 4}}
+			]},
+			{"id": 6, "type": "graph", "title": "Temperature", "repeat": "node", "gridPos": {"x": 0, "y": 21, "w": 24, "h": 4}}
+		]
+	}`), &spec))
+
+	repeated := Repeat(&spec, map[string]Selection{
+		"gpu":  {All: true},
+		"node": {Values: []string{"node-1", "node-2"}},
+	})
+	req.Equal([]string{
+		"1 GPU 0 0,0 12x8",
+		"7 GPU 1 12,0 12x8",
+		"8 GPU 2 0,8 12x8",
+		"2 Power 0,16 24x8",
+		"3 Node node-1 0,24 24x1",
+		"4 Load of node-1 0,25 12x4",
+		"9 Load of node-1 0,29 12x4",
+		"10 Load of node-1 0,33 12x4",
+		"5 Memory of node-1 12,25 12x4",
+		"11 Node node-2 0,37 24x1",
+		"12 Load of node-2 0,38 12x4",
+		"13 Load of node-2 0,42 12x4",
+		"14 Load of node-2 0,46 12x4",
+		"15 Memory of node-2 12,38 12x4",
+		"6 Temperature 0,50 12x4",
+		"16 Temperature 12,50 12x4",
+	}, layout(repeated))
+
+	req.Equal(`DCGM_FI_DEV_GPU_TEMP{gpu="1"}`, repeated[1].Targets[0].Expression)
+	req.Equal("GPU 1", repeated[1].Targets[0].LegendFormat)
+	req.Equal("/d/gpu?var-gpu=1", repeated[1].Links[0].URL)
+	req.Empty(repeated[1].Repeat)
+	req.Equal("gpu", spec.Panels[0].Repeat, "the spec is left as it is")
+
+	queries := Queries(&spec, QueryOptions{Selected: map[string]Selection{"gpu": {Values: []string{"0", "2"}}}})
+	req.Len(queries, 2)
+	req.Equal(int64(7), queries[1].PanelID)
+	req.Equal("GPU 2", queries[1].PanelTitle)
+	req.Equal(`DCGM_FI_DEV_GPU_TEMP{gpu="2"}`, queries[1].Expression)
+}
+
+func TestRepeatWithoutValues(t *testing.T) {
+	req := require.New(t)
+
+	spec := &v1alpha2.DashboardSpec{
+		Templatings: variables(t, `[{"name": "node", "type": "query", "query": "label_values(node)", "includeAll": true}]`),
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "graph", Title: "Load of $node", Repeat: "node"}},
+			{CommonPanel: panels.CommonPanel{Id: 2, Type: "graph", Title: "Load of $pod", Repeat: "pod"}},
+		},
+	}
+	req.Equal(spec.Panels, Repeat(spec, map[string]Selection{"node": {All: true}}))
+}
+
+func TestRepeatMoreThanTheColumns(t *testing.T) {
+	req := require.New(t)
+
+	values := make([]string, 30)
+	for i := range values {
+		values[i] = fmt.Sprint(i)
+	}
+	spec := &v1alpha2.DashboardSpec{
+		Templatings: variables(t, `[{"name": "pod", "type": "query", "query": "label_values(pod)", "multi": true}]`),
+		Panels: []*panels.Panel{
+			{CommonPanel: panels.CommonPanel{Id: 1, Type: "stat", Title: "$pod", Repeat: "pod", MaxPerRow: 40,
+				GridPos: &panels.GridPos{W: 24, H: 2}}},
+		},
+	}
+
+	positions := layout(Repeat(spec, map[string]Selection{"pod": {Values: values}}))
+	req.Len(positions, 30)
+	req.Equal("1 0 0,0 1x2", positions[0])
+	req.Equal("24 23 23,0 1x2", positions[23])
+	req.Equal("25 24 0,2 1x2", positions[24])
+	req.Equal("30 29 5,2 1x2", positions[29])
+}
+
+func TestRepeatColumnLinks(t *testing.T) {
+	req := require.New(t)
+
+	spec := &v1alpha2.DashboardSpec{
+		Templatings: variables(t, `[{"name": "namespace", "type": "custom", "multi": true, "options": [
+			{"text": "default", "value": "default"}, {"text": "kube system", "value": "kube system"}
+		]}]`),
+		Panels: []*panels.Panel{{
+			CommonPanel: panels.CommonPanel{Id: 1, Type: "table", Title: "Pods of $namespace", Repeat: "namespace"},
+			TablePanel: &panels.TablePanel{Styles: []panels.ColumnStyle{
+				{Pattern: "pod", Link: &panels.ColumnLink{URL: "/d/pod?var-namespace=$namespace&var-pod=${__cell}"}},
+			}},
+		}},
+	}
+	repeated := Repeat(spec, map[string]Selection{"namespace": {Values: []string{"default", "kube system"}}})
+	req.Len(repeated, 2)
+	req.Equal("/d/pod?var-namespace=kube+system&var-pod=${__cell}", repeated[1].TablePanel.Styles[0].Link.URL)
+	req.Equal("/d/pod?var-namespace=$namespace&var-pod=${__cell}", spec.Panels[0].TablePanel.Styles[0].Link.URL)
+}